@@ -0,0 +1,123 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	abbrDate   string
+	abbrOutput string
+)
+
+// abbrZone is a timezone using an abbreviation.
+type abbrZone struct {
+	Timezone string `json:"timezone"`
+	Offset   string `json:"offset"`
+}
+
+// abbrResolution is the timezones using an abbreviation on a date. Ambiguous is true if they don't all share the same
+// UTC offset, i.e. IST is used in India, Ireland, and Israel.
+type abbrResolution struct {
+	Abbreviation string     `json:"abbreviation"`
+	Date         string     `json:"date"`
+	Ambiguous    bool       `json:"ambiguous"`
+	Offsets      []string   `json:"offsets"`
+	Timezones    []abbrZone `json:"timezones"`
+}
+
+// resolveAbbreviation returns the timezones using the abbreviation on the date, found by scanning every known timezone
+// so the result follows daylight saving time. Links are skipped so each zone is only listed once.
+func resolveAbbreviation(abbreviation, date string) abbrResolution {
+	r := abbrResolution{Abbreviation: strings.ToUpper(abbreviation), Date: date, Offsets: []string{}, Timezones: []abbrZone{}}
+	for _, tz := range timezonesAll {
+		if _, ok := resolveTimezoneLink(tz); ok {
+			continue
+		}
+		zone, err := getZoneInfo(context.Background(), tz, date, l)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(zone.abbreviation, abbreviation) {
+			continue
+		}
+		offset := formatSecondsOffset(zone.offsetMinutes * 60)
+		r.Timezones = append(r.Timezones, abbrZone{Timezone: tz, Offset: offset})
+		r.Offsets = append(r.Offsets, offset)
+	}
+	r.Offsets = deduplicateSlice(r.Offsets)
+	r.Ambiguous = len(r.Offsets) > 1
+	return r
+}
+
+var abbrCmd = &cobra.Command{
+	Use:   "abbr <abbreviation>",
+	Short: "List the time zones using an abbreviation",
+	Long: `List the time zones using an abbreviation, like CET or IST, on today's date or the one given with --date. The list is
+built from tzdata, so it reflects daylight saving time, i.e. CEST replaces CET in the summer. Abbreviations used at more
+than one UTC offset are flagged as ambiguous. Exits with status 1 if no time zone uses the abbreviation.
+
+Examples:
+
+  # List the time zones using IST, which is ambiguous:
+  $ timeBuddy abbr IST
+
+  # List the time zones using CET in January as JSON:
+  $ timeBuddy abbr CET --date 2025-01-15 --output json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		d := time.Now().Format(time.DateOnly)
+		if cmd.Flags().Changed("date") {
+			resolved, err := resolveRelativeDate(abbrDate)
+			if err != nil {
+				l.Fatal().Str("date", abbrDate).Err(err).Send()
+			}
+			if _, err := time.Parse(time.DateOnly, resolved); err != nil {
+				l.Fatal().Str("date", abbrDate).Err(err).Send()
+			}
+			d = resolved
+		}
+
+		r := resolveAbbreviation(args[0], d)
+		if len(r.Timezones) == 0 {
+			l.Fatal().Str("abbreviation", args[0]).Str("date", d).Err(fmt.Errorf("no timezones use the abbreviation")).Send()
+		}
+
+		switch abbrOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(r); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			if r.Ambiguous {
+				fmt.Printf("%s is ambiguous, it is used at %d UTC offsets: %s\n\n", r.Abbreviation, len(r.Offsets), strings.Join(r.Offsets, ", "))
+			}
+			width := 0
+			for _, z := range r.Timezones {
+				width = max(width, len(z.Timezone))
+			}
+			for _, z := range r.Timezones {
+				fmt.Printf("%-*s  %s\n", width, z.Timezone, z.Offset)
+			}
+		default:
+			l.Fatal().Str("output", abbrOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(abbrCmd)
+	abbrCmd.Flags().StringVarP(&abbrDate, "date", "d", "", "``date to resolve the abbreviation on. Accepts the same values as timeBuddy --date. Defaults to today.")
+	abbrCmd.Flags().StringVarP(&abbrOutput, "output", "o", "text", "``output format, text or json")
+}
@@ -0,0 +1,222 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aliasFormat string
+	aliasIcon   string
+)
+
+// timezoneAlias is a short name for a timezone, i.e. nyc for America/New_York.
+type timezoneAlias struct {
+	Alias    string `json:"alias"`
+	Timezone string `json:"timezone"`
+	Offset   string `json:"offset"`
+}
+
+// configuredAliases returns the aliases saved in the config file, keyed by lowercase alias. Viper lowercases map keys,
+// so aliases are case-insensitive.
+func configuredAliases() map[string]string {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	return aliasTimezones(fv.GetStringMap("aliases"))
+}
+
+// aliasTimezones returns the timezone of each alias in the aliases config key. An alias is either a timezone, or a map
+// with the timezone under iana and an icon under icon, i.e. {iana: America/New_York, icon: 🗽}.
+func aliasTimezones(aliases map[string]interface{}) map[string]string {
+	tzs := make(map[string]string, len(aliases))
+	for alias, val := range aliases {
+		if m, ok := val.(map[string]interface{}); ok {
+			tzs[alias], _ = m["iana"].(string)
+		} else {
+			tzs[alias] = fmt.Sprint(val)
+		}
+	}
+	return tzs
+}
+
+// aliasIcons returns the icon of each alias in the aliases config key that sets one.
+func aliasIcons() map[string]string {
+	icons := map[string]string{}
+	for alias, val := range v.GetStringMap("aliases") {
+		if m, ok := val.(map[string]interface{}); ok {
+			if icon, ok := m["icon"].(string); ok && icon != "" {
+				icons[alias] = icon
+			}
+		}
+	}
+	return icons
+}
+
+// resolveAlias returns the timezone an alias points to, or the name unchanged if it isn't an alias.
+func resolveAlias(name string) string {
+	if tz, ok := aliasTimezones(v.GetStringMap("aliases"))[strings.ToLower(name)]; ok {
+		return tz
+	}
+	return name
+}
+
+// completeAliases completes the names of the aliases saved in the config file.
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	aliases := configuredAliases()
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// newTimezoneAlias returns the alias with the current UTC offset of its timezone.
+func newTimezoneAlias(alias, tz string) timezoneAlias {
+	a := timezoneAlias{Alias: alias, Timezone: tz}
+	if loc, err := loadLocation(tz); err == nil {
+		a.Offset = time.Now().In(loc).Format("-07:00")
+	}
+	return a
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage short names for time zones",
+	Long: `Manage aliases, short names that can be used in place of a time zone with timeBuddy --timezone. Aliases are saved
+in the config file and are case-insensitive.
+
+Examples:
+
+  # Add an alias, then use it:
+  $ timeBuddy alias add nyc America/New_York
+  $ timeBuddy --timezone nyc
+
+  # Add an alias with its own icon for timeBuddy --icon:
+  $ timeBuddy alias add nyc America/New_York --icon 🗽
+
+  # List the aliases:
+  $ timeBuddy alias list`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <alias> <timezone>",
+	Short: "Add an alias for a time zone",
+	Args:  cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return timezonesAll, cobra.ShellCompDirectiveDefault
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		alias, tz := strings.ToLower(args[0]), args[1]
+		if strings.ContainsAny(alias, "./ ") {
+			l.Fatal().Str("alias", args[0]).Err(fmt.Errorf("alias can't contain dots, slashes, or spaces")).Send()
+		}
+		if _, err := loadLocation(tz); err != nil {
+			l.Fatal().Str("timezone", tz).Err(err).Send()
+		}
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		// an alias with an icon is saved as a map, otherwise as just the timezone
+		if aliasIcon != "" {
+			fv.Set("aliases."+alias, map[string]interface{}{"iana": tz, "icon": aliasIcon})
+		} else {
+			fv.Set("aliases."+alias, tz)
+		}
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:               "remove <alias>",
+	Short:             "Remove an alias",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		if err := removeConfigKey(fv, "aliases."+strings.ToLower(args[0])); err != nil {
+			l.Fatal().Str("alias", args[0]).Err(fmt.Errorf("alias is not defined")).Send()
+		}
+	},
+}
+
+var aliasShowCmd = &cobra.Command{
+	Use:               "show <alias>",
+	Short:             "Print the time zone an alias points to",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	Run: func(cmd *cobra.Command, args []string) {
+		tz, ok := configuredAliases()[strings.ToLower(args[0])]
+		if !ok {
+			l.Fatal().Str("alias", args[0]).Err(fmt.Errorf("alias is not defined")).Send()
+		}
+		fmt.Println(tz)
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the aliases and their time zones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		configured := configuredAliases()
+		aliases := make([]timezoneAlias, 0, len(configured))
+		for alias, tz := range configured {
+			aliases = append(aliases, newTimezoneAlias(alias, tz))
+		}
+		sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+
+		switch aliasFormat {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(aliases); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "table":
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.Style().Format.Header = text.FormatDefault
+			t.AppendHeader(table.Row{"Alias", "Timezone", "UTC Offset"})
+			for _, a := range aliases {
+				t.AppendRow(table.Row{a.Alias, a.Timezone, a.Offset})
+			}
+			t.Render()
+		default:
+			l.Fatal().Str("format", aliasFormat).Err(fmt.Errorf("invalid format, expected table or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasAddCmd, aliasRemoveCmd, aliasShowCmd, aliasListCmd)
+	aliasAddCmd.Flags().StringVar(&aliasIcon, "icon", "", "``icon shown before the alias's row with timeBuddy --icon, instead of its country's flag")
+	aliasListCmd.Flags().StringVarP(&aliasFormat, "format", "f", "table", "``output format, table or json")
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// parseAtSpec parses a moment given as "[date] time [timezone]", i.e. "2024-11-05 15:00 Australia/Sydney". The date
+// accepts the same values as --date and defaults to today in the timezone. The time accepts the same values as --time.
+// The timezone may be an alias and defaults to the local timezone. The returned timezone is empty if none was given.
+func parseAtSpec(spec string) (t time.Time, timezone string, err error) {
+	fields := strings.Fields(spec)
+
+	var day string
+	if len(fields) > 0 {
+		if resolved, err := resolveRelativeDate(fields[0]); err == nil {
+			if _, err := time.Parse(time.DateOnly, resolved); err == nil {
+				day, fields = resolved, fields[1:]
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return time.Time{}, "", fmt.Errorf("missing time in %q, expected a format like \"[date] 15:00 [timezone]\"", spec)
+	}
+
+	// the time may contain spaces, i.e. "11 a.m.", so only treat the last field as a timezone if the rest isn't a time
+	timeStr := strings.Join(fields, " ")
+	hour, minute, err := parseTimeString(timeStr)
+	if err != nil && len(fields) > 1 {
+		timezone = fields[len(fields)-1]
+		timeStr = strings.Join(fields[:len(fields)-1], " ")
+		hour, minute, err = parseTimeString(timeStr)
+	}
+	if err != nil {
+		// parseTimeString's errors already name the time that failed
+		return time.Time{}, "", err
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		loc, err = loadLocation(resolveAlias(timezone))
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+	if day == "" {
+		day = time.Now().In(loc).Format(time.DateOnly)
+	}
+	d, _ := time.Parse(time.DateOnly, day)
+	return time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, loc), timezone, nil
+}
+
+var atCmd = &cobra.Command{
+	Use:   "at <[date] time [timezone]>",
+	Short: "Show the table at a moment in a time zone",
+	Long: `Show the table for the time zones in the config file, highlighting the given moment. The date accepts the same
+values as --date and defaults to today, the time accepts the same values as --time, and the time zone defaults to your
+local time zone. A time zone that isn't in the config file is added to the table.
+
+Examples:
+
+  # Show the table at 3pm on Nov 5th in Sydney:
+  $ timeBuddy at "2024-11-05 15:00 Australia/Sydney"
+
+  # Show the table at 9am today in London:
+  $ timeBuddy at 9am Europe/London
+
+  # Show the table at 3:30pm local time:
+  $ timeBuddy at 15:30`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTimezone,
+	Run: func(cmd *cobra.Command, args []string) {
+		spec := strings.Join(args, " ")
+		t, timezone, err := parseAtSpec(spec)
+		if err != nil {
+			l.Fatal().Str("at", spec).Err(err).Send()
+		}
+
+		// the table highlights --date and --time in the local timezone, so convert the moment to local time
+		t = t.Local()
+		date = t.Format(time.DateOnly)
+		timeOfDay = t.Format("15:04")
+		specifiedHour, specifiedMinute = t.Hour(), t.Minute()
+
+		loadTableSettings()
+		timezones = includeLocalTimezone(v.GetStringSlice("timezone"))
+		if timezone != "" {
+			timezones = append(timezones, timezone)
+		}
+		zones, err := processTimezones(cmd.Context(), deduplicateSlice(timezones), date, l)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		printTimeTable(os.Stdout, zones, colorEnabled)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(atCmd)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchFormat    string
+	batchTimezones []string
+)
+
+// batchEvent is a single converted event written by the batch command.
+type batchEvent struct {
+	Line         int    `json:"line"`
+	Source       string `json:"source"`
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	Time         string `json:"time"`
+}
+
+// parseBatchLine parses a single line of batch input in the format "YYYY-MM-DD HH:MM <timezone>".
+// It returns the date, time, and timezone fields, along with the timezone's location, or an error if the line is
+// malformed, the date or time is invalid, or the timezone can't be resolved. Timezones are resolved like those of the
+// table, so aliases, UTC offsets, city names, and names typed in any case are accepted.
+func parseBatchLine(line string) (date, clock, tz string, loc *time.Location, err error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", "", "", nil, fmt.Errorf("expected 'YYYY-MM-DD HH:MM <timezone>', got %d fields", len(fields))
+	}
+	date, clock, tz = fields[0], fields[1], fields[2]
+
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return "", "", "", nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	if _, err := time.Parse("15:04", clock); err != nil {
+		return "", "", "", nil, fmt.Errorf("invalid time %q: %w", clock, err)
+	}
+	z, err := getZoneInfo(context.Background(), tz, date, l)
+	if err != nil {
+		return "", "", "", nil, err
+	}
+	return date, clock, tz, z.currentTime.Location(), nil
+}
+
+// convertBatchLine converts the event described by a batch input line, in the location its timezone was resolved to
+// by parseBatchLine, to each of the target timezones. The line number is carried along so the output can be matched
+// back to the input.
+func convertBatchLine(lineNum int, date, clock, tz string, loc *time.Location, targets []string) ([]batchEvent, error) {
+	t, err := parseDateTime(date+" "+clock, loc)
+	if err != nil {
+		return nil, err
+	}
+	conversions, err := convertTime(t, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("%s %s %s", date, clock, tz)
+	events := make([]batchEvent, 0, len(conversions))
+	for _, c := range conversions {
+		events = append(events, batchEvent{
+			Line:         lineNum,
+			Source:       source,
+			Timezone:     c.Timezone,
+			Abbreviation: c.Abbreviation,
+			Time:         c.Time,
+		})
+	}
+	return events, nil
+}
+
+// runBatch reads events from r line-by-line, converts each to the target timezones, and writes the results to w in
+// CSV or JSON lines format. Lines that can't be parsed are reported to stderr with their line number and skipped.
+func runBatch(r io.Reader, w io.Writer, targets []string, outputFormat string) error {
+	var write func(batchEvent) error
+	switch outputFormat {
+	case "csv":
+		cw := csv.NewWriter(w)
+		defer cw.Flush()
+		if err := cw.Write([]string{"line", "source", "timezone", "abbreviation", "time"}); err != nil {
+			return err
+		}
+		write = func(e batchEvent) error {
+			err := cw.Write([]string{fmt.Sprintf("%d", e.Line), e.Source, e.Timezone, e.Abbreviation, e.Time})
+			cw.Flush()
+			return err
+		}
+	case "json":
+		enc := json.NewEncoder(w)
+		write = func(e batchEvent) error {
+			return enc.Encode(e)
+		}
+	default:
+		return fmt.Errorf("invalid format %q, expected csv or json", outputFormat)
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		// skip blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		date, clock, tz, loc, err := parseBatchLine(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", lineNum, err)
+			continue
+		}
+		events, err := convertBatchLine(lineNum, date, clock, tz, loc, targets)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", lineNum, err)
+			continue
+		}
+		for _, e := range events {
+			if err := write(e); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Convert a list of events read from stdin",
+	Long: `Read events from stdin, one per line, and convert each of them to all of the configured time zones.
+
+Each line must be in the format: YYYY-MM-DD HH:MM <timezone>, i.e. 2024-06-15 15:00 America/New_York. Blank lines and
+lines starting with # are ignored. Lines that can't be parsed are reported to stderr along with their line number and
+processing continues with the next line.
+
+The target time zones default to those saved in the config file. Output is written one line per input event per target
+time zone, in either CSV or JSON lines format.
+
+Examples:
+
+  # Convert a list of meeting proposals to the configured time zones:
+  $ cat meetings.txt | timeBuddy batch
+
+  # Convert a single event to specific time zones as JSON:
+  $ echo "2024-06-15 15:00 America/New_York" | timeBuddy batch --format json -z Europe/London -z Asia/Tokyo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(batchTimezones) == 0 {
+			l.Fatal().Err(fmt.Errorf("no target timezones configured, use --timezone to specify one")).Send()
+		}
+
+		// resolve the target timezones up front rather than on every line, and validate them before reading any input
+		for i, tz := range batchTimezones {
+			z, err := getZoneInfo(cmd.Context(), tz, date, l)
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			batchTimezones[i] = z.name
+		}
+		batchTimezones = deduplicateSlice(batchTimezones)
+
+		if err := runBatch(os.Stdin, os.Stdout, batchTimezones, batchFormat); err != nil {
+			l.Fatal().Err(err).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVarP(&batchFormat, "format", "f", "csv", "``output format, csv or json")
+	batchCmd.Flags().StringArrayVarP(&batchTimezones, "timezone", "z", []string{}, "``timezone to convert to. Can be used multiple times. Defaults to the timezones in the config file.")
+	err := batchCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func Test_runBatch(t *testing.T) {
+	oldViper := v
+	t.Cleanup(func() { v = oldViper })
+	v = viper.New()
+	v.Set("aliases", map[string]any{"tyo": "Asia/Tokyo"})
+
+	input := strings.Join([]string{
+		"2024-06-15 15:00 europe/paris",
+		"2024-06-15 15:00 UTC+5:30",
+		"2024-06-15 15:00 tyo",
+		"2024-06-15 15:00 Bogus/Zone",
+		"2024-06-15 15:00 tokyo",
+	}, "\n")
+	want := strings.Join([]string{
+		"line,source,timezone,abbreviation,time",
+		"1,2024-06-15 15:00 europe/paris,America/New_York,EDT,2024-06-15 09:00",
+		"2,2024-06-15 15:00 UTC+5:30,America/New_York,EDT,2024-06-15 05:30",
+		"3,2024-06-15 15:00 tyo,America/New_York,EDT,2024-06-15 02:00",
+		"5,2024-06-15 15:00 tokyo,America/New_York,EDT,2024-06-15 02:00",
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	stderr := captureStderr(t, func() {
+		if err := runBatch(strings.NewReader(input), &out, []string{"America/New_York"}, "csv"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if out.String() != want {
+		t.Errorf("runBatch() =\n%s\nwant\n%s", out.String(), want)
+	}
+	if !strings.Contains(stderr, `line 4: invalid timezone "Bogus/Zone"`) {
+		t.Errorf("stderr = %q, want line 4 reported", stderr)
+	}
+}
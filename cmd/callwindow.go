@@ -0,0 +1,123 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import "fmt"
+
+const minutesPerDay = 24 * 60
+
+// callZone is a timezone's UTC offset and working hours, all in minutes, used to find a call window between two zones.
+type callZone struct {
+	name          string
+	offsetMinutes int
+	workStart     int
+	workEnd       int
+}
+
+// callZoneFromDetail returns the callZone for a timezone detail, using its configured working hours or the default.
+func callZoneFromDetail(z timezoneDetail) callZone {
+	wh := zoneWorkingHours(z, defaultWorkingHours)
+	return callZone{name: z.name, offsetMinutes: z.offsetMinutes, workStart: wh[0], workEnd: wh[1]}
+}
+
+// callWindow is a range of UTC minutes. end may be past minutesPerDay when the window wraps past UTC midnight.
+type callWindow struct {
+	start int
+	end   int
+}
+
+// inWorkHours reports whether the UTC minute falls inside the zone's working hours.
+func (z callZone) inWorkHours(utcMinute int) bool {
+	local := ((utcMinute+z.offsetMinutes)%minutesPerDay + minutesPerDay) % minutesPerDay
+	return inWorkingHours(local, 1, [2]int{z.workStart, z.workEnd})
+}
+
+// outsideMinutes returns how many minutes the UTC minute is from the zone's working hours, 0 if it is inside them.
+func (z callZone) outsideMinutes(utcMinute int) int {
+	if z.inWorkHours(utcMinute) {
+		return 0
+	}
+	local := ((utcMinute+z.offsetMinutes)%minutesPerDay + minutesPerDay) % minutesPerDay
+	// distance to the start of the next working day or back to the end of the previous one, whichever is closer
+	toStart := ((z.workStart-local)%minutesPerDay + minutesPerDay) % minutesPerDay
+	fromEnd := ((local-z.workEnd+1)%minutesPerDay + minutesPerDay) % minutesPerDay
+	return min(toStart, fromEnd)
+}
+
+// bestCallWindow returns the longest range of UTC minutes during which both zones are inside working hours. If there is
+// no overlap, ok is false and compromise is the UTC minute, on a half hour boundary, that is the fewest total minutes
+// outside of working hours for the two zones.
+func bestCallWindow(a, b callZone) (best callWindow, ok bool, compromise int) {
+	// check in 15 minute steps so zones with :30 and :45 offsets line up
+	const step = 15
+	inBoth := func(m int) bool { return a.inWorkHours(m) && b.inWorkHours(m) }
+
+	// start scanning at a minute outside the overlap so a window wrapping past UTC midnight isn't split in two
+	origin := -1
+	for m := 0; m < minutesPerDay; m += step {
+		if !inBoth(m) {
+			origin = m
+			break
+		}
+	}
+	if origin < 0 {
+		// both zones work around the clock
+		return callWindow{start: 0, end: minutesPerDay}, true, 0
+	}
+
+	current := callWindow{start: -1}
+	for i := 0; i <= minutesPerDay; i += step {
+		m := origin + i
+		if i < minutesPerDay && inBoth(m) {
+			if current.start < 0 {
+				current.start = m
+			}
+			continue
+		}
+		if current.start >= 0 {
+			current.end = m
+			if !ok || current.end-current.start > best.end-best.start {
+				best, ok = current, true
+			}
+			current.start = -1
+		}
+	}
+	if ok {
+		length := best.end - best.start
+		best.start %= minutesPerDay
+		best.end = best.start + length
+		return best, true, 0
+	}
+
+	fewest := -1
+	for m := 0; m < minutesPerDay; m += 30 {
+		if outside := a.outsideMinutes(m) + b.outsideMinutes(m); fewest < 0 || outside < fewest {
+			fewest = outside
+			compromise = m
+		}
+	}
+	return callWindow{}, false, compromise
+}
+
+// formatMinute formats minutes since midnight as HH:MM, wrapping past midnight.
+func formatMinute(m int) string {
+	m = (m%minutesPerDay + minutesPerDay) % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// formatCallWindow returns a one line description of the best call window between two zones, i.e.
+// best overlap: 14:00–17:00 UTC (09:00–12:00 New York / 15:00–18:00 London)
+func formatCallWindow(a, b callZone) string {
+	best, ok, compromise := bestCallWindow(a, b)
+	if !ok {
+		return fmt.Sprintf("no overlap within working hours; closest compromise %s UTC (%s %s / %s %s)",
+			formatMinute(compromise),
+			formatMinute(compromise+a.offsetMinutes), zoneDisplayName(a.name),
+			formatMinute(compromise+b.offsetMinutes), zoneDisplayName(b.name))
+	}
+	return fmt.Sprintf("best overlap: %s–%s UTC (%s–%s %s / %s–%s %s)",
+		formatMinute(best.start), formatMinute(best.end),
+		formatMinute(best.start+a.offsetMinutes), formatMinute(best.end+a.offsetMinutes), zoneDisplayName(a.name),
+		formatMinute(best.start+b.offsetMinutes), formatMinute(best.end+b.offsetMinutes), zoneDisplayName(b.name))
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import "testing"
+
+func Test_bestCallWindow(t *testing.T) {
+	// working hours of 09:00-17:00 in each zone, at its offset in June
+	zone := func(name string, offsetMinutes int) callZone {
+		return callZone{name: name, offsetMinutes: offsetMinutes, workStart: 9 * 60, workEnd: 17 * 60}
+	}
+	tests := []struct {
+		name           string
+		a, b           callZone
+		want           callWindow
+		wantOK         bool
+		wantCompromise int
+	}{
+		{
+			name:   "full overlap",
+			a:      zone("UTC", 0),
+			b:      zone("Africa/Abidjan", 0),
+			want:   callWindow{start: 9 * 60, end: 17 * 60},
+			wantOK: true,
+		},
+		{
+			name:   "partial overlap",
+			a:      zone("America/New_York", -4*60),
+			b:      zone("Europe/London", 60),
+			want:   callWindow{start: 13 * 60, end: 16 * 60},
+			wantOK: true,
+		},
+		{
+			name:   "partial overlap with a half hour offset",
+			a:      zone("Europe/London", 60),
+			b:      zone("Asia/Kolkata", 5*60+30),
+			want:   callWindow{start: 8 * 60, end: 11*60 + 30},
+			wantOK: true,
+		},
+		{
+			name:   "overlap past UTC midnight",
+			a:      zone("Pacific/Auckland", 12*60),
+			b:      zone("Australia/Sydney", 10*60),
+			want:   callWindow{start: 23 * 60, end: 29 * 60},
+			wantOK: true,
+		},
+		{
+			name: "no overlap with a half hour offset",
+			a:    zone("America/Los_Angeles", -7*60),
+			b:    zone("Asia/Kolkata", 5*60+30),
+			// from 00:00 to 03:30 UTC Los Angeles has just finished and Kolkata is about to start, 211 minutes outside
+			// working hours in total, the fewest of any half hour
+			wantCompromise: 0,
+		},
+		{
+			name: "no overlap",
+			a:    zone("America/Los_Angeles", -7*60),
+			b:    zone("Asia/Tokyo", 9*60),
+			// the working day in Los Angeles ends at 00:00 UTC, just as the one in Tokyo starts
+			wantCompromise: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, compromise := bestCallWindow(tt.a, tt.b)
+			if ok != tt.wantOK {
+				t.Fatalf("bestCallWindow() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("bestCallWindow() = %s-%s, want %s-%s", formatMinute(got.start), formatMinute(got.end), formatMinute(tt.want.start), formatMinute(tt.want.end))
+			}
+			if !ok && compromise != tt.wantCompromise {
+				t.Errorf("bestCallWindow() compromise = %s, want %s", formatMinute(compromise), formatMinute(tt.wantCompromise))
+			}
+			// the window is the same whichever zone is given first
+			if got2, ok2, compromise2 := bestCallWindow(tt.b, tt.a); got2 != got || ok2 != ok || compromise2 != compromise {
+				t.Errorf("bestCallWindow() with the zones swapped = %v, %v, %d, want %v, %v, %d", got2, ok2, compromise2, got, ok, compromise)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// cityTimezones maps major cities and capitals that aren't part of a timezone name to the timezones they are in, keyed
+// by their name folded with foldCityName. Cities named in a timezone, like Paris, are found from the name instead. A
+// name shared by cities in different timezones, like Portland, lists each of them.
+var cityTimezones = map[string][]string{
+	// North America
+	"atlanta":          {"America/New_York"},
+	"austin":           {"America/Chicago"},
+	"boston":           {"America/New_York"},
+	"calgary":          {"America/Edmonton"},
+	"dallas":           {"America/Chicago"},
+	"houston":          {"America/Chicago"},
+	"las vegas":        {"America/Los_Angeles"},
+	"miami":            {"America/New_York"},
+	"minneapolis":      {"America/Chicago"},
+	"montreal":         {"America/Toronto"},
+	"ottawa":           {"America/Toronto"},
+	"philadelphia":     {"America/New_York"},
+	"portland":         {"America/Los_Angeles", "America/New_York"},
+	"quebec":           {"America/Toronto"},
+	"salt lake city":   {"America/Denver"},
+	"san diego":        {"America/Los_Angeles"},
+	"san francisco":    {"America/Los_Angeles"},
+	"san jose":         {"America/Los_Angeles", "America/Costa_Rica"},
+	"seattle":          {"America/Los_Angeles"},
+	"st louis":         {"America/Chicago"},
+	"washington":       {"America/New_York"},
+	"washington dc":    {"America/New_York"},
+	"guadalajara":      {"America/Mexico_City"},
+	"brasilia":         {"America/Sao_Paulo"},
+	"rio de janeiro":   {"America/Sao_Paulo"},
+	"medellin":         {"America/Bogota"},
+	"quito":            {"America/Guayaquil"},
+	"sucre":            {"America/La_Paz"},
+	"san juan":         {"America/Puerto_Rico"},
+	"kingston":         {"America/Jamaica"},
+	"havana":           {"America/Havana"},
+	"santo domingo":    {"America/Santo_Domingo"},
+	"guatemala city":   {"America/Guatemala"},
+	"san salvador":     {"America/El_Salvador"},
+	"tegucigalpa":      {"America/Tegucigalpa"},
+	"managua":          {"America/Managua"},
+	"panama city":      {"America/Panama"},
+	"ciudad de mexico": {"America/Mexico_City"},
+
+	// Europe
+	"antwerp":       {"Europe/Brussels"},
+	"barcelona":     {"Europe/Madrid"},
+	"bern":          {"Europe/Zurich"},
+	"birmingham":    {"Europe/London"},
+	"cologne":       {"Europe/Berlin"},
+	"edinburgh":     {"Europe/London"},
+	"florence":      {"Europe/Rome"},
+	"frankfurt":     {"Europe/Berlin"},
+	"geneva":        {"Europe/Zurich"},
+	"glasgow":       {"Europe/London"},
+	"gothenburg":    {"Europe/Stockholm"},
+	"hamburg":       {"Europe/Berlin"},
+	"krakow":        {"Europe/Warsaw"},
+	"lyon":          {"Europe/Paris"},
+	"manchester":    {"Europe/London"},
+	"marseille":     {"Europe/Paris"},
+	"milan":         {"Europe/Rome"},
+	"munich":        {"Europe/Berlin"},
+	"naples":        {"Europe/Rome"},
+	"nice":          {"Europe/Paris"},
+	"porto":         {"Europe/Lisbon"},
+	"rotterdam":     {"Europe/Amsterdam"},
+	"seville":       {"Europe/Madrid"},
+	"st petersburg": {"Europe/Moscow"},
+	"the hague":     {"Europe/Amsterdam"},
+	"valencia":      {"Europe/Madrid"},
+	"venice":        {"Europe/Rome"},
+	"ankara":        {"Europe/Istanbul"},
+
+	// Asia
+	"abu dhabi":   {"Asia/Dubai"},
+	"astana":      {"Asia/Almaty"},
+	"bangalore":   {"Asia/Kolkata"},
+	"bengaluru":   {"Asia/Kolkata"},
+	"beijing":     {"Asia/Shanghai"},
+	"busan":       {"Asia/Seoul"},
+	"cebu":        {"Asia/Manila"},
+	"chengdu":     {"Asia/Shanghai"},
+	"chennai":     {"Asia/Kolkata"},
+	"delhi":       {"Asia/Kolkata"},
+	"denpasar":    {"Asia/Makassar"},
+	"doha":        {"Asia/Qatar"},
+	"guangzhou":   {"Asia/Shanghai"},
+	"hanoi":       {"Asia/Ho_Chi_Minh"},
+	"hyderabad":   {"Asia/Kolkata", "Asia/Karachi"},
+	"islamabad":   {"Asia/Karachi"},
+	"kyoto":       {"Asia/Tokyo"},
+	"lahore":      {"Asia/Karachi"},
+	"male":        {"Indian/Maldives"},
+	"mumbai":      {"Asia/Kolkata"},
+	"naypyidaw":   {"Asia/Yangon"},
+	"new delhi":   {"Asia/Kolkata"},
+	"osaka":       {"Asia/Tokyo"},
+	"pune":        {"Asia/Kolkata"},
+	"saigon":      {"Asia/Ho_Chi_Minh"},
+	"shenzhen":    {"Asia/Shanghai"},
+	"tel aviv":    {"Asia/Jerusalem"},
+	"bali":        {"Asia/Makassar"},
+	"kuwait city": {"Asia/Kuwait"},
+
+	// Africa
+	"abuja":        {"Africa/Lagos"},
+	"cape town":    {"Africa/Johannesburg"},
+	"durban":       {"Africa/Johannesburg"},
+	"marrakesh":    {"Africa/Casablanca"},
+	"pretoria":     {"Africa/Johannesburg"},
+	"rabat":        {"Africa/Casablanca"},
+	"yamoussoukro": {"Africa/Abidjan"},
+
+	// Oceania
+	"canberra":     {"Australia/Sydney"},
+	"christchurch": {"Pacific/Auckland"},
+	"gold coast":   {"Australia/Brisbane"},
+	"wellington":   {"Pacific/Auckland"},
+}
+
+var (
+	// cityIndex maps folded city names to their canonical timezones, from cityTimezones and the city in each timezone
+	// name. It is built the first time a city is looked up.
+	cityIndex     map[string][]string
+	cityIndexOnce sync.Once
+)
+
+// foldCityName lowercases a city name and removes its diacritics and punctuation, so "São Paulo", "sao paulo", and
+// Sao_Paulo all fold to "sao paulo".
+func foldCityName(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn))), s)
+	if err != nil {
+		folded = s
+	}
+	folded = strings.Map(func(r rune) rune {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			return ' '
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return -1
+		}
+	}, folded)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// buildCityIndex builds cityIndex. Links are replaced by the timezone they point to, so a city in both a link and its
+// timezone, like Calcutta and Kolkata, isn't ambiguous.
+func buildCityIndex() {
+	cityIndex = map[string][]string{}
+	add := func(city, tz string) {
+		if canonical, ok := resolveTimezoneLink(tz); ok {
+			tz = canonical
+		}
+		for _, existing := range cityIndex[city] {
+			if existing == tz {
+				return
+			}
+		}
+		cityIndex[city] = append(cityIndex[city], tz)
+	}
+	for _, tz := range timezonesAll {
+		// names without a region, like EST or Japan, aren't cities
+		if i := strings.LastIndex(tz, "/"); i >= 0 && !strings.HasPrefix(tz, "Etc/") {
+			add(foldCityName(tz[i+1:]), tz)
+		}
+	}
+	for city, tzs := range cityTimezones {
+		for _, tz := range tzs {
+			add(city, tz)
+		}
+	}
+}
+
+// resolveCity returns the timezone of a city, and whether the name is a known city. A city in more than one timezone
+// is an error listing them.
+func resolveCity(name string) (string, bool, error) {
+	cityIndexOnce.Do(buildCityIndex)
+	tzs, ok := cityIndex[foldCityName(name)]
+	if !ok {
+		return "", false, nil
+	}
+	if len(tzs) > 1 {
+		candidates := append([]string{}, tzs...)
+		sort.Strings(candidates)
+		return "", true, fmt.Errorf("%q is a city in more than one timezone, use one of %s", name, strings.Join(candidates, ", "))
+	}
+	return tzs[0], true, nil
+}
+
+// resolveTimezoneNames replaces each timezone in tzs typed with a different case or separators with its canonical
+// spelling, i.e. america/new_york with America/New_York, and each city name with its timezone, i.e. Paris with
+// Europe/Paris, so the timezone is what's shown and saved. Aliases are left alone, as are names that are neither, so
+// they fail validation as before.
+func resolveTimezoneNames(tzs []string) ([]string, error) {
+	resolved := make([]string, len(tzs))
+	for i, tz := range tzs {
+		resolved[i] = tz
+		if resolveAlias(tz) != tz {
+			continue
+		}
+		if canonical, ok := matchTimezoneName(tz); ok {
+			resolved[i] = canonical
+			continue
+		}
+		if _, err := loadLocation(tz); err == nil {
+			continue
+		}
+		city, ok, err := resolveCity(tz)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			l.Info().Str("city", tz).Str("timezone", city).Msg("Resolved city to timezone:")
+			resolved[i] = city
+		}
+	}
+	return deduplicateSlice(resolved), nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupDryRun bool
+	cleanupYes    bool
+)
+
+// findCleanupArtifacts returns the files timeBuddy has created: the config file and any backups of it. Only paths that
+// exist are returned. The config path comes from getConfigPath, the same resolution used when reading the config, so
+// nothing outside of what timeBuddy wrote is ever matched.
+func findCleanupArtifacts() ([]string, error) {
+	var artifacts []string
+	configPath := getConfigPath()
+	if _, err := os.Stat(configPath); err == nil {
+		artifacts = append(artifacts, configPath)
+	}
+
+	backups, err := filepath.Glob(configPath + ".bak*")
+	if err != nil {
+		return nil, err
+	}
+	artifacts = append(artifacts, backups...)
+	return artifacts, nil
+}
+
+// confirm asks the user a yes/no question and reports whether they answered yes.
+func confirm(r *bufio.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N]: ", question)
+	answer, err := r.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+var cleanupCmd = &cobra.Command{
+	Use:     "cleanup",
+	Aliases: []string{"uninstall"},
+	Short:   "Remove the config file and its backups",
+	Long: `Remove the files timeBuddy has created: the config file and any backups of it. Nothing else is touched.
+
+Each file is confirmed before it is removed unless --yes is provided. Use --dry-run to list the files without removing
+them.
+
+Examples:
+
+  # List the files that would be removed:
+  $ timeBuddy cleanup --dry-run
+
+  # Remove the files without confirmation:
+  $ timeBuddy cleanup --yes`,
+	// Override the root command's PersistentPreRunE so the config file isn't created just to be removed
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		artifacts, err := findCleanupArtifacts()
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		if len(artifacts) == 0 {
+			fmt.Println("Nothing to clean up.")
+			return
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		for _, path := range artifacts {
+			if cleanupDryRun {
+				fmt.Printf("Would remove: %s\n", path)
+				continue
+			}
+			if !cleanupYes && !confirm(reader, os.Stdout, fmt.Sprintf("Remove %s?", path)) {
+				fmt.Printf("Skipped: %s\n", path)
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				l.Error().Str("path", path).Err(err).Send()
+				continue
+			}
+			fmt.Printf("Removed: %s\n", path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+	cleanupCmd.Flags().BoolVarP(&cleanupDryRun, "dry-run", "n", false, "list the files that would be removed without removing them")
+	cleanupCmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "remove files without asking for confirmation")
+}
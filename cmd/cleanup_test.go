@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_cleanupCmd(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("config directory doesn't follow XDG_CONFIG_HOME on", runtime.GOOS)
+	}
+	useTestConfig(t, "")
+	configFile = ""
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	dir := filepath.Join(home, ".config", configDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, configFileName+"."+configType)
+	artifacts := []string{path, path + ".bak", path + ".bak.2025-01-15T143000"}
+	// files timeBuddy didn't write are never matched, even next to the config file
+	others := []string{filepath.Join(dir, "notes.txt"), filepath.Join(home, ".config", "other.yaml")}
+	for _, f := range append(slices.Clone(artifacts), others...) {
+		if err := os.WriteFile(f, []byte("timezone: []\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := executeCommand(t, "cleanup", "--dry-run")
+	for _, f := range artifacts {
+		if !strings.Contains(out, "Would remove: "+f) {
+			t.Errorf("dry run output doesn't list %s:\n%s", f, out)
+		}
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("dry run removed %s", f)
+		}
+	}
+
+	out = executeCommand(t, "cleanup", "--yes")
+	for _, f := range artifacts {
+		if !strings.Contains(out, "Removed: "+f) {
+			t.Errorf("output doesn't list %s:\n%s", f, out)
+		}
+		if _, err := os.Stat(f); err == nil {
+			t.Errorf("%s wasn't removed", f)
+		}
+	}
+	for _, f := range others {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("%s was removed", f)
+		}
+	}
+
+	if out := executeCommand(t, "cleanup", "--yes"); !strings.Contains(out, "Nothing to clean up.") {
+		t.Errorf("output after cleaning up = %q, want Nothing to clean up.", out)
+	}
+}
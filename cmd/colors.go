@@ -0,0 +1,143 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// ANSI SGR parameters that introduce 256-color and truecolor foregrounds and backgrounds
+const (
+	fgExtended text.Color = 38
+	bgExtended text.Color = 48
+	color256   text.Color = 5
+	colorRGB   text.Color = 2
+)
+
+// block characters used to shade cells when color is disabled
+const (
+	shadeNight = "▓"
+	shadeDawn  = "▒"
+	shadeDay   = "░"
+)
+
+// default backgrounds for night and dawn hours, dark enough for white text. Day hours use the normal background.
+const (
+	defaultNightBg = "236"
+	defaultDawnBg  = "60"
+)
+
+// dayNightColors are the backgrounds used to shade hour cells by the time of day. A nil Colors leaves the cell's
+// background alone.
+type dayNightColors struct {
+	night text.Colors
+	dawn  text.Colors
+	day   text.Colors
+}
+
+// dayNightBucket is the part of the day an hour falls in: night is 22:00-06:59, dawn, which also covers the evening,
+// is 07:00-08:59 and 18:00-21:59, and day is 09:00-17:59.
+type dayNightBucket int
+
+const (
+	bucketNight dayNightBucket = iota
+	bucketDawn
+	bucketDay
+)
+
+// bucketForHour returns the part of the day a local hour falls in.
+func bucketForHour(localHour int) dayNightBucket {
+	switch {
+	case localHour >= 9 && localHour <= 17:
+		return bucketDay
+	case localHour >= 7 && localHour <= 8, localHour >= 18 && localHour <= 21:
+		return bucketDawn
+	default:
+		return bucketNight
+	}
+}
+
+// parseBackgroundColor parses a terminal color, either a 256-color code from 0 to 255 or a truecolor #RRGGBB value,
+// and returns the background color for it. An empty string returns nil, the terminal's normal background.
+func parseBackgroundColor(s string) (text.Colors, error) {
+	return parseExtendedColor(s, bgExtended)
+}
+
+// parseForegroundColor parses a terminal color like parseBackgroundColor, and returns the text color for it.
+func parseForegroundColor(s string) (text.Colors, error) {
+	return parseExtendedColor(s, fgExtended)
+}
+
+// parseExtendedColor parses a 256-color code or #RRGGBB value into the escape codes for it, as a foreground or
+// background depending on the layer, fgExtended or bgExtended.
+func parseExtendedColor(s string, layer text.Color) (text.Colors, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return nil, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+		}
+		return text.Colors{layer, colorRGB, text.Color(rgb >> 16 & 0xff), text.Color(rgb >> 8 & 0xff), text.Color(rgb & 0xff)}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 255 {
+		return nil, fmt.Errorf("invalid color %q, expected a color code from 0 to 255 or #RRGGBB", s)
+	}
+	return text.Colors{layer, color256, text.Color(n)}, nil
+}
+
+// parseDayNightColors parses the night, dawn, and day background colors. Night and dawn are meant to be dark, so their
+// text is shown in white.
+func parseDayNightColors(night, dawn, day string) (dayNightColors, error) {
+	var colors dayNightColors
+	var err error
+	if colors.night, err = parseBackgroundColor(night); err != nil {
+		return colors, fmt.Errorf("night color: %w", err)
+	}
+	if colors.dawn, err = parseBackgroundColor(dawn); err != nil {
+		return colors, fmt.Errorf("dawn color: %w", err)
+	}
+	if colors.night != nil {
+		colors.night = append(colors.night, text.FgHiWhite)
+	}
+	if colors.dawn != nil {
+		colors.dawn = append(colors.dawn, text.FgHiWhite)
+	}
+	if colors.day, err = parseBackgroundColor(day); err != nil {
+		return colors, fmt.Errorf("day color: %w", err)
+	}
+	return colors, nil
+}
+
+// cellColorForHour returns the background color for a cell showing the given local hour.
+func cellColorForHour(localHour int, colors dayNightColors) text.Colors {
+	switch bucketForHour(localHour) {
+	case bucketDay:
+		return colors.day
+	case bucketDawn:
+		return colors.dawn
+	default:
+		return colors.night
+	}
+}
+
+// shadePatternForHour returns the block character used to shade a cell showing the given local hour when color is
+// disabled, ▓ for night, ▒ for dawn and evening, and ░ for day.
+func shadePatternForHour(localHour int) string {
+	switch bucketForHour(localHour) {
+	case bucketDay:
+		return shadeDay
+	case bucketDawn:
+		return shadeDawn
+	default:
+		return shadeNight
+	}
+}
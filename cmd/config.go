@@ -0,0 +1,566 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configBackupOutput string
+	configProfile      string
+	configResetYes     bool
+)
+
+// configKeys are the top level keys that can be managed with the config subcommand
+var configKeys = []string{"ampm-style", "color", "date-format", "emoji", "hour-bands", "icon", "no-local", "recently_used_limit", "shade", "timezone", "twelve-hour"}
+
+// configMapKeys are the keys holding a map of timezone to value, set as <key>.<timezone>, i.e. working_hours.Asia/Tokyo
+var configMapKeys = []string{"labels", "working_hours"}
+
+// configEnvName returns the environment variable that overrides a top level config key, i.e. TIMEBUDDY_TWELVE_HOUR.
+func configEnvName(key string) string {
+	return "TIMEBUDDY_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// setInEnv reports whether a top level config key is overridden by an environment variable. The timezone key is also
+// overridden by TIMEBUDDY_TIMEZONES.
+func setInEnv(key string) bool {
+	names := []string{configEnvName(key)}
+	if key == "timezone" {
+		names = append(names, "TIMEBUDDY_TIMEZONES")
+	}
+	for _, name := range names {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutEnvSettings returns a viper instance holding cv's settings, except that each flag filled in from an
+// environment variable has its value from the config file, or is left out if the file doesn't have one. viper always
+// returns the environment's value, so it's written from the copy instead, so the environment's value isn't saved.
+func withoutEnvSettings(cv *viper.Viper) (*viper.Viper, error) {
+	if len(envFlags) == 0 {
+		return cv, nil
+	}
+	fv, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	settings := cv.AllSettings()
+	for name := range envFlags {
+		if fv.IsSet(name) {
+			settings[name] = fv.Get(name)
+		} else {
+			delete(settings, name)
+		}
+	}
+	nv := viper.New()
+	if err := nv.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	return nv, nil
+}
+
+// readConfigFile returns a viper instance holding only the contents of the config file. Changes are made on it instead
+// of the global instance so values from environment variables aren't written to the file.
+func readConfigFile() (*viper.Viper, error) {
+	fv := viper.New()
+	fv.SetConfigFile(getConfigPath())
+	fv.SetConfigType(configType)
+	if err := fv.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return fv, nil
+}
+
+// atomicWriteConfig writes the settings in cv to the config file at filePath, stamped with the current schema version.
+// The settings are written to a temporary file in the same directory, which then replaces the config file, so a crash
+// mid-write can't leave it truncated.
+func atomicWriteConfig(cv *viper.Viper, filePath string) error {
+	cv.Set("schema_version", currentSchemaVersion)
+	settings := cv.AllSettings()
+	for _, key := range schemaMapKeys {
+		if _, ok := settings[key]; !ok {
+			settings[key] = map[string]interface{}{}
+		}
+	}
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filePath), configName+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	// the temporary file is removed if anything fails before it replaces the config file
+	defer os.Remove(tmpPath)
+
+	// keep the config file's permissions, rather than the temporary file's 0600
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(filePath); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// parseConfigValue validates a value for a config key and converts it to the type stored in the config file.
+func parseConfigValue(key, value string) (interface{}, error) {
+	mapKey, _, nested := strings.Cut(key, ".")
+	if nested {
+		if !slices.Contains(configMapKeys, mapKey) {
+			return nil, fmt.Errorf("unknown config key %q, expected one of %s, or %s.<timezone>", key, strings.Join(configKeys, ", "), strings.Join(configMapKeys, ".<timezone>, "))
+		}
+		if mapKey == "working_hours" {
+			if _, err := parseWorkingHours(value); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+
+	switch key {
+	case "color", "emoji", "hour-bands", "icon", "no-local", "shade", "twelve-hour":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s, expected true or false", value, key)
+		}
+		return b, nil
+	case "timezone":
+		var tzs []string
+		for _, tz := range strings.Split(value, ",") {
+			tz = strings.TrimSpace(tz)
+			if tz == "" {
+				continue
+			}
+			if _, err := loadLocation(tz); err != nil {
+				return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+			}
+			tzs = append(tzs, tz)
+		}
+		return deduplicateSlice(tzs), nil
+	case "recently_used_limit":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid value %q for %s, expected a number 0 or greater", value, key)
+		}
+		return n, nil
+	case "ampm-style":
+		if _, err := parseAMPMStyle(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "date-format":
+		format := strings.ToLower(value)
+		if format != dateFormatMDY && format != dateFormatDMY {
+			return nil, fmt.Errorf("invalid value %q for %s, expected %s or %s", value, key, dateFormatMDY, dateFormatDMY)
+		}
+		return format, nil
+	default:
+		return nil, fmt.Errorf("unknown config key %q, expected one of %s, or %s.<timezone>", key, strings.Join(configKeys, ", "), strings.Join(configMapKeys, ".<timezone>, "))
+	}
+}
+
+// formatConfigValue formats a config value for printing. Lists are comma separated, the same format config set accepts.
+func formatConfigValue(val interface{}) string {
+	switch val := val.(type) {
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = fmt.Sprintf("%v", item)
+		}
+		return strings.Join(items, ",")
+	case []string:
+		return strings.Join(val, ",")
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// deleteConfigKey removes a key, which may be nested using dots, from a map of settings. It reports whether the key
+// was found.
+func deleteConfigKey(settings map[string]interface{}, key string) bool {
+	parent, child, nested := strings.Cut(key, ".")
+	if !nested {
+		if _, ok := settings[key]; !ok {
+			return false
+		}
+		delete(settings, key)
+		return true
+	}
+	m, ok := settings[parent].(map[string]interface{})
+	if !ok || !deleteConfigKey(m, child) {
+		return false
+	}
+	if len(m) == 0 {
+		delete(settings, parent)
+	}
+	return true
+}
+
+// removeConfigKey removes a key, which may be nested using dots, from the config file read into fv. viper can't remove
+// a key, and setting a map without the key leaves it in place, so the remaining settings are written from a fresh
+// instance.
+func removeConfigKey(fv *viper.Viper, key string) error {
+	settings := fv.AllSettings()
+	if !deleteConfigKey(settings, key) {
+		return fmt.Errorf("config key is not set")
+	}
+	nv := viper.New()
+	nv.SetConfigFile(getConfigPath())
+	nv.SetConfigType(configType)
+	if err := nv.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	return atomicWriteConfig(nv, getConfigPath())
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set values in the config file",
+	Long: `Get, set, and remove values in the config file without editing it by hand. The config file is the same one the
+table reads and writes, and its location is listed in timeBuddy --help.
+
+Examples:
+
+  # List every value and where it comes from:
+  $ timeBuddy config list
+
+  # Set the time zones shown by default:
+  $ timeBuddy config set timezone Local,America/New_York,Asia/Tokyo
+
+  # Set working hours for a time zone, then remove them:
+  $ timeBuddy config set working_hours.America/New_York 09:00-17:00
+  $ timeBuddy config unset working_hours.America/New_York`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a config key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !v.IsSet(args[0]) {
+			l.Fatal().Str("key", args[0]).Err(fmt.Errorf("config key is not set")).Send()
+		}
+		fmt.Println(formatConfigValue(v.Get(args[0])))
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a config key. The timezone key accepts a comma separated list.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.ToLower(args[0])
+		val, err := parseConfigValue(key, args[1])
+		if err != nil {
+			l.Fatal().Str("key", args[0]).Err(err).Send()
+		}
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		fv.Set(key, val)
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a config key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		if err := removeConfigKey(fv, strings.ToLower(args[0])); err != nil {
+			l.Fatal().Str("key", args[0]).Err(err).Send()
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the effective config values and their source",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		keys := v.AllKeys()
+		// keys only set in the environment aren't known to viper until they are read
+		for _, key := range configKeys {
+			if _, ok := os.LookupEnv(configEnvName(key)); ok && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			source := "config"
+			if !strings.Contains(key, ".") {
+				if _, ok := os.LookupEnv(configEnvName(key)); ok {
+					source = "env " + configEnvName(key)
+				}
+			}
+			fmt.Printf("%s=%s (%s)\n", key, formatConfigValue(v.Get(key)), source)
+		}
+	},
+}
+
+// profileKey returns the config key holding a profile, exiting if the profile doesn't exist.
+func profileKey(fv *viper.Viper, name string) string {
+	key := "profiles." + strings.ToLower(name)
+	if !fv.IsSet(key) {
+		l.Fatal().Str("profile", name).Err(fmt.Errorf("profile is not defined")).Send()
+	}
+	return key
+}
+
+// timestampedBackupPath returns the path of a backup of the config file named with the current time, so timeBuddy
+// cleanup finds it along with other backups.
+func timestampedBackupPath() string {
+	return getConfigPath() + ".bak." + time.Now().Format("2006-01-02T150405")
+}
+
+// mergeConfigMap merges the overlay's map at key into the merged settings, with the overlay's values taking precedence.
+// It returns a description of each entry that was added, and of each conflicting entry that was replaced.
+func mergeConfigMap(merged, base, overlay *viper.Viper, key string) (changes, conflicts []string) {
+	names := make([]string, 0)
+	for name := range overlay.GetStringMap(key) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		k := key + "." + name
+		value := overlay.Get(k)
+		if !base.IsSet(k) {
+			changes = append(changes, fmt.Sprintf("added %s = %s", k, formatConfigValue(value)))
+		} else if old := formatConfigValue(base.Get(k)); old != formatConfigValue(value) {
+			conflicts = append(conflicts, fmt.Sprintf("replaced %s = %s with %s", k, old, formatConfigValue(value)))
+		} else {
+			continue
+		}
+		merged.Set(k, value)
+	}
+	return changes, conflicts
+}
+
+// mergeConfigs merges the timezone, groups, aliases, and working_hours keys of the overlay into a copy of base. The
+// timezone lists are combined, keeping base's order, and map entries in both are taken from the overlay. Other keys are
+// left as they are in base. It returns a description of each addition, and of each conflicting entry, which is also
+// logged.
+func mergeConfigs(base, overlay *viper.Viper) (merged *viper.Viper, changes, conflicts []string) {
+	merged = viper.New()
+	if err := merged.MergeConfigMap(base.AllSettings()); err != nil {
+		l.Fatal().Err(err).Send()
+	}
+
+	tzs := base.GetStringSlice("timezone")
+	for _, tz := range overlay.GetStringSlice("timezone") {
+		if !slices.Contains(tzs, tz) {
+			tzs = append(tzs, tz)
+			changes = append(changes, "added timezone "+tz)
+		}
+	}
+	merged.Set("timezone", tzs)
+
+	for _, key := range []string{"groups", "aliases", "working_hours"} {
+		c, cf := mergeConfigMap(merged, base, overlay, key)
+		changes = append(changes, c...)
+		conflicts = append(conflicts, cf...)
+	}
+	for _, c := range conflicts {
+		l.Warn().Str("conflict", c).Msg("Merge conflict:")
+	}
+	return merged, changes, conflicts
+}
+
+var configMergeCmd = &cobra.Command{
+	Use:   "merge <file|->",
+	Short: "Merge time zones, groups, aliases, and working hours from another config file",
+	Long: `Merge the timezone, groups, aliases, and working_hours keys from another config file, or stdin if the file is -,
+into the config file, i.e. to pick up a teammate's groups. Time zones are added to the end of the list. Groups, aliases,
+and working hours that are in both files are taken from the other file. Other keys are ignored. A backup of the config
+file is written before it is changed, and a summary of the changes is printed.
+
+Examples:
+
+  # Merge a teammate's config file:
+  $ timeBuddy config merge team.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		settings, err := readSettings(args[0])
+		if err != nil {
+			l.Fatal().Str("file", args[0]).Err(err).Send()
+		}
+		overlay := viper.New()
+		if err := overlay.MergeConfigMap(settings); err != nil {
+			l.Fatal().Str("file", args[0]).Err(err).Send()
+		}
+		if invalid := invalidTimezoneRefs(configuredTimezoneRefs(overlay)); len(invalid) > 0 {
+			l.Fatal().Str("file", args[0]).Err(fmt.Errorf("invalid timezones: %s", strings.Join(invalid, ", "))).Send()
+		}
+
+		path := getConfigPath()
+		base, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", path).Err(err).Send()
+		}
+		merged, changes, conflicts := mergeConfigs(base, overlay)
+		if len(changes) == 0 && len(conflicts) == 0 {
+			fmt.Println("Nothing to merge.")
+			return
+		}
+
+		if content, err := os.ReadFile(path); err == nil {
+			backup := timestampedBackupPath()
+			if err := os.WriteFile(backup, content, 0o644); err != nil {
+				l.Fatal().Str("backup", backup).Err(err).Send()
+			}
+			fmt.Printf("Backed up %s to %s\n", path, backup)
+		}
+		if err := atomicWriteConfig(merged, path); err != nil {
+			l.Fatal().Str("configFile", path).Err(err).Send()
+		}
+		for _, c := range append(changes, conflicts...) {
+			fmt.Println(c)
+		}
+	},
+}
+
+var configResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Restore the default config file",
+	Long: `Replace the config file with a fresh default one, after asking for confirmation unless --yes is given. The current
+config file is kept alongside it with a .bak suffix. With --profile, only that profile is removed.
+
+Examples:
+
+  # Start over with a default config file:
+  $ timeBuddy config reset
+
+  # Remove a profile without asking:
+  $ timeBuddy config reset --profile work --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := getConfigPath()
+		if configProfile != "" {
+			fv, err := readConfigFile()
+			if err != nil {
+				l.Fatal().Str("configFile", path).Err(err).Send()
+			}
+			key := profileKey(fv, configProfile)
+			if !configResetYes && !confirm(bufio.NewReader(os.Stdin), os.Stdout, fmt.Sprintf("Remove profile %s from %s?", configProfile, path)) {
+				return
+			}
+			if err := removeConfigKey(fv, key); err != nil {
+				l.Fatal().Str("profile", configProfile).Err(err).Send()
+			}
+			fmt.Println(path)
+			return
+		}
+
+		if !configResetYes && !confirm(bufio.NewReader(os.Stdin), os.Stdout, fmt.Sprintf("Reset %s? The current file will be kept as %s.bak.", path, path)) {
+			return
+		}
+		if err := os.Rename(path, path+".bak"); err != nil && !os.IsNotExist(err) {
+			l.Fatal().Str("configFile", path).Err(err).Send()
+		}
+		if err := atomicWriteConfig(viper.New(), path); err != nil {
+			l.Fatal().Str("configFile", path).Err(err).Send()
+		}
+		fmt.Println(path)
+	},
+}
+
+var configBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Copy the config file to a timestamped backup",
+	Long: `Copy the config file to a backup next to it named with the current time, i.e. config.yaml.bak.2025-01-15T143000, or
+to the file given with --output. With --profile, only that profile is backed up. Backups can be loaded with
+timeBuddy import.
+
+Examples:
+
+  # Back up the config file:
+  $ timeBuddy config backup
+
+  # Back up a profile to a specific file:
+  $ timeBuddy config backup --profile work --output work.yaml`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := getConfigPath()
+		output := configBackupOutput
+		if output == "" {
+			output = timestampedBackupPath()
+		}
+
+		var content []byte
+		var err error
+		if configProfile != "" {
+			fv, err := readConfigFile()
+			if err != nil {
+				l.Fatal().Str("configFile", path).Err(err).Send()
+			}
+			key := profileKey(fv, configProfile)
+			content, err = yaml.Marshal(map[string]interface{}{
+				"profiles": map[string]interface{}{strings.ToLower(configProfile): fv.GetStringSlice(key)},
+			})
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		} else if content, err = os.ReadFile(path); err != nil {
+			l.Fatal().Str("configFile", path).Err(err).Send()
+		}
+
+		if err := os.WriteFile(output, content, 0o644); err != nil {
+			l.Fatal().Str("output", output).Err(err).Send()
+		}
+		fmt.Println(output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configMergeCmd, configResetCmd, configBackupCmd)
+	configResetCmd.Flags().BoolVarP(&configResetYes, "yes", "y", false, "reset without asking for confirmation")
+	configBackupCmd.Flags().StringVarP(&configBackupOutput, "output", "o", "", "``file to write the backup to. Defaults to a timestamped file next to the config file.")
+	for _, c := range []*cobra.Command{configResetCmd, configBackupCmd} {
+		c.Flags().StringVarP(&configProfile, "profile", "p", "", "``profile to reset or back up instead of the whole config file")
+		if err := c.RegisterFlagCompletionFunc("profile", completeProfiles); err != nil {
+			l.Error().Err(err).Send()
+		}
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfig is a config file with timezones and two profiles, for the config subcommand tests.
+const testConfig = `schema_version: 1
+timezone:
+  - Asia/Tokyo
+  - Europe/London
+profiles:
+  home:
+    - Europe/Vilnius
+  work:
+    - America/New_York
+    - Australia/Sydney
+`
+
+func Test_configResetCmd(t *testing.T) {
+	path := useTestConfig(t, testConfig)
+	out := executeCommand(t, "config", "reset", "--yes")
+	if strings.TrimSpace(out) != path {
+		t.Errorf("output = %q, want %q", out, path)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != testConfig {
+		t.Errorf("backup = %q, want the old config file %q", backup, testConfig)
+	}
+	fv, err := readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fv.IsSet("timezone") || fv.IsSet("profiles") {
+		t.Errorf("config file still holds %v after reset", fv.AllSettings())
+	}
+	if got := fv.GetInt("schema_version"); got != currentSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", got, currentSchemaVersion)
+	}
+}
+
+func Test_configResetCmd_profile(t *testing.T) {
+	path := useTestConfig(t, testConfig)
+	executeCommand(t, "config", "reset", "--profile", "Work", "--yes")
+
+	fv, err := readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fv.IsSet("profiles.work") {
+		t.Errorf("profile work wasn't removed")
+	}
+	if got := fv.GetStringSlice("profiles.home"); !slices.Equal(got, []string{"Europe/Vilnius"}) {
+		t.Errorf("profile home = %v, want [Europe/Vilnius]", got)
+	}
+	if got := fv.GetStringSlice("timezone"); !slices.Equal(got, []string{"Asia/Tokyo", "Europe/London"}) {
+		t.Errorf("timezone = %v, want [Asia/Tokyo Europe/London]", got)
+	}
+	if _, err := os.Stat(path + ".bak"); err == nil {
+		t.Errorf("resetting a profile backed up the config file")
+	}
+}
+
+func Test_configBackupCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// output is the name of the file given with --output, or empty for a timestamped backup
+		output string
+		want   string
+	}{
+		{
+			name: "timestamped backup",
+			want: testConfig,
+		},
+		{
+			name:   "--output",
+			output: "backup.yaml",
+			want:   testConfig,
+		},
+		{
+			name:   "--profile",
+			args:   []string{"--profile", "work"},
+			output: "work.yaml",
+			want:   "profiles:\n    work:\n        - America/New_York\n        - Australia/Sydney\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTestConfig(t, testConfig)
+			args := append([]string{"config", "backup"}, tt.args...)
+			if tt.output != "" {
+				args = append(args, "--output", filepath.Join(filepath.Dir(path), tt.output))
+			}
+			backup := strings.TrimSpace(executeCommand(t, args...))
+
+			if tt.output == "" {
+				if !strings.HasPrefix(backup, path+".bak.") {
+					t.Errorf("backup = %s, want a timestamped file next to %s", backup, path)
+				}
+			} else if backup != filepath.Join(filepath.Dir(path), tt.output) {
+				t.Errorf("backup = %s, want %s", backup, tt.output)
+			}
+			content, err := os.ReadFile(backup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("backup content = %q, want %q", content, tt.want)
+			}
+			// the config file itself is left alone
+			if content, err := os.ReadFile(path); err != nil || string(content) != testConfig {
+				t.Errorf("config file = %q, %v, want it unchanged", content, err)
+			}
+		})
+	}
+}
+
+func Test_configSetCmd(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  interface{}
+	}{
+		{key: "date-format", value: "DMY", want: "dmy"},
+		{key: "hour-bands", value: "true", want: true},
+		{key: "icon", value: "true", want: true},
+		{key: "no-local", value: "true", want: true},
+		{key: "shade", value: "false", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			useTestConfig(t, testConfig)
+			executeCommand(t, "config", "set", tt.key, tt.value)
+			fv, err := readConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fv.Get(tt.key); got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_configSetCmd_dateFormat(t *testing.T) {
+	// a date with slashes whose month and day could be in either order is only read once date-format is set
+	useTestConfig(t, testConfig)
+	if _, _, err := parseLocaleDate("03/04/2024"); err == nil {
+		t.Fatal("parseLocaleDate() of an ambiguous date without date-format succeeded")
+	}
+	executeCommand(t, "config", "set", "date-format", "dmy")
+	got, _, err := parseLocaleDate("03/04/2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Format(time.DateOnly) != "2024-04-03" {
+		t.Errorf("parseLocaleDate() = %s, want 2024-04-03", got.Format(time.DateOnly))
+	}
+	if _, err := parseConfigValue("date-format", "ymd"); err == nil {
+		t.Errorf("parseConfigValue() of date-format ymd succeeded")
+	}
+}
+
+func Test_atomicWriteConfig_failure(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup makes writing the config file at path fail
+		setup func(t *testing.T, path string)
+	}{
+		{
+			name: "read-only directory",
+			setup: func(t *testing.T, path string) {
+				if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+					t.Skip("a read-only directory is still writable on windows and as root")
+				}
+				dir := filepath.Dir(path)
+				if err := os.Chmod(dir, 0o555); err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+			},
+		},
+		{
+			// the temporary file is written, but can't replace a directory
+			name: "config file is replaced by a directory",
+			setup: func(t *testing.T, path string) {
+				if err := os.Remove(path); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Join(path, "keep"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTestConfig(t, testConfig)
+			before, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fv, err := readConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fv.Set("timezone", []string{"UTC"})
+			tt.setup(t, path)
+			original, _ := os.ReadFile(path)
+
+			if err := atomicWriteConfig(fv, path); err == nil {
+				t.Fatal("atomicWriteConfig() succeeded, want an error")
+			}
+
+			if content, _ := os.ReadFile(path); string(content) != string(original) {
+				t.Errorf("config file = %q, want it unchanged %q", content, original)
+			}
+			after, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(after) != len(before) {
+				t.Errorf("config directory holds %v, want only %v, no temporary file", after, before)
+			}
+		})
+	}
+}
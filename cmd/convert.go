@@ -0,0 +1,167 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertFrom   string
+	convertTo     []string
+	convertOutput string
+)
+
+// conversion is the equivalent of a source time in a single target timezone.
+type conversion struct {
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	Time         string `json:"time"`
+	DayShift     int    `json:"day_shift"`
+}
+
+// parseDateTime parses a "YYYY-MM-DD HH:MM" or "HH:MM" string in the given location. When only a time is provided,
+// today's date in that location is implied.
+func parseDateTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation(time.DateOnly+" 15:04", s, loc); err == nil {
+		return t, nil
+	}
+	clock, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected 'YYYY-MM-DD HH:MM' or 'HH:MM'", s)
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
+
+// dayShift returns the number of calendar days between the dates of a and b, as seen in their own locations.
+// i.e. 23:00 in New York is 1 day ahead when viewed as 13:00 the next day in Tokyo.
+func dayShift(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// formatDayShift returns a human readable description of a day shift, or an empty string if there is none.
+func formatDayShift(shift int) string {
+	switch {
+	case shift == 1:
+		return "(next day)"
+	case shift == -1:
+		return "(previous day)"
+	case shift > 1:
+		return fmt.Sprintf("(+%d days)", shift)
+	case shift < -1:
+		return fmt.Sprintf("(%d days)", shift)
+	default:
+		return ""
+	}
+}
+
+// convertTime converts t to each of the timezones provided. Each timezone is resolved the same way as the table's, with
+// getZoneInfo, so aliases, UTC offsets, city names, and names typed in any case are accepted.
+func convertTime(t time.Time, timezones []string) ([]conversion, error) {
+	conversions := make([]conversion, 0, len(timezones))
+	for _, name := range timezones {
+		z, err := getZoneInfo(context.Background(), name, t.Format(time.DateOnly), l)
+		if err != nil {
+			return nil, err
+		}
+		lt := t.In(z.currentTime.Location())
+		abbreviation, _ := lt.Zone()
+		conversions = append(conversions, conversion{
+			Timezone:     z.name,
+			Abbreviation: abbreviation,
+			Time:         lt.Format(time.DateOnly + " 15:04"),
+			DayShift:     dayShift(t, lt),
+		})
+	}
+	return conversions, nil
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <time>",
+	Short: "Convert a time from one time zone to others",
+	Long: `Convert a single date and time from one time zone to a list of other time zones.
+
+The time must be in the format 'YYYY-MM-DD HH:MM' or 'HH:MM', in which case today's date is implied. The source time
+zone defaults to your local time zone and the target time zones default to those saved in the config file. Times that
+fall on a different day than the source time are marked, i.e. "(next day)".
+
+Examples:
+
+  # Convert 3pm in New York on June 15th to the time zones in the config file:
+  $ timeBuddy convert "2024-06-15 15:00" --from America/New_York
+
+  # Convert 9am today in your local time zone to specific time zones:
+  $ timeBuddy convert 09:00 --to Europe/London --to Asia/Tokyo
+
+  # Output the conversion as JSON:
+  $ timeBuddy convert "2024-06-15 15:00" --from America/New_York --output json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		src, err := getZoneInfo(cmd.Context(), convertFrom, timeNow().Format(time.DateOnly), l)
+		if err != nil {
+			l.Fatal().Str("timezone", convertFrom).Err(err).Send()
+		}
+		t, err := parseDateTime(args[0], src.currentTime.Location())
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+
+		targets := convertTo
+		if !cmd.Flags().Changed("to") {
+			targets = v.GetStringSlice("timezone")
+		}
+		targets = deduplicateSlice(targets)
+		if len(targets) == 0 {
+			l.Fatal().Err(fmt.Errorf("no target timezones configured, use --to to specify one")).Send()
+		}
+
+		conversions, err := convertTime(t, targets)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+
+		switch convertOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(conversions); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			width := 0
+			for _, c := range conversions {
+				width = max(width, len(c.Timezone))
+			}
+			for _, c := range conversions {
+				line := fmt.Sprintf("%-*s  %s %-5s %s", width, c.Timezone, c.Time, c.Abbreviation, formatDayShift(c.DayShift))
+				fmt.Println(strings.TrimRight(line, " "))
+			}
+		default:
+			l.Fatal().Str("output", convertOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringVarP(&convertFrom, "from", "f", "Local", "``timezone the time is in. Defaults to the local timezone.")
+	convertCmd.Flags().StringArrayVarP(&convertTo, "to", "t", []string{}, "``timezone to convert to. Can be used multiple times. Defaults to the timezones in the config file.")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "text", "``output format, text or json")
+	for _, name := range []string{"from", "to"} {
+		if err := convertCmd.RegisterFlagCompletionFunc(name, completeTimezone); err != nil {
+			l.Error().Err(err).Send()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func Test_convertTime(t *testing.T) {
+	oldViper := v
+	t.Cleanup(func() { v = oldViper })
+	v = viper.New()
+	v.Set("aliases", map[string]any{"hq": "America/New_York"})
+
+	// 23:00 on 2024-06-15 in Tokyo
+	src := time.Date(2024, 6, 15, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timezone string
+		want     conversion
+		wantErr  bool
+	}{
+		{timezone: "Europe/Paris", want: conversion{Timezone: "Europe/Paris", Abbreviation: "CEST", Time: "2024-06-15 16:00"}},
+		{timezone: "europe/paris", want: conversion{Timezone: "Europe/Paris", Abbreviation: "CEST", Time: "2024-06-15 16:00"}},
+		{timezone: "paris", want: conversion{Timezone: "Europe/Paris", Abbreviation: "CEST", Time: "2024-06-15 16:00"}},
+		{timezone: "hq", want: conversion{Timezone: "America/New_York", Abbreviation: "EDT", Time: "2024-06-15 10:00"}},
+		{timezone: "UTC+5:45", want: conversion{Timezone: "UTC+5:45", Abbreviation: "UTC+5:45", Time: "2024-06-15 19:45"}},
+		{timezone: "Pacific/Kiritimati", want: conversion{Timezone: "Pacific/Kiritimati", Abbreviation: "+14", Time: "2024-06-16 04:00", DayShift: 1}},
+		{timezone: "Bogus/Zone", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			got, err := convertTime(src, []string{tt.timezone})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("convertTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got[0] != tt.want {
+				t.Errorf("convertTime() = %+v, want %+v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertCmd_from(t *testing.T) {
+	useTestConfig(t, "")
+	// the source timezone is resolved like the table's, so a city name works
+	out := executeCommand(t, "convert", "2024-06-15 15:00", "--from", "tokyo", "--to", "Europe/London")
+	if want := "Europe/London  2024-06-15 07:00 BST\n"; out != want {
+		t.Errorf("convert output = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// demoLocalTimezone stands in for the local timezone so the demo doesn't depend on the machine it runs on
+	demoLocalTimezone = "America/New_York"
+	demoDate          = "2024-06-14"
+	demoTime          = "10:30"
+)
+
+// demoTimezones are the timezones shown by the demo, in order
+var demoTimezones = []string{"America/New_York", "Europe/London", "Asia/Tokyo", "Australia/Sydney"}
+
+// demoWorkingHours are the working hours applied to the demo so dimmed cells show up in screenshots
+var demoWorkingHours = map[string][2]int{
+	"america/new_york": {9 * 60, 17 * 60},
+	"europe/london":    {9 * 60, 17 * 60},
+}
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Print a fixed example table for screenshots and docs",
+	Long: `Print the table for a fixed set of time zones at a fixed date and time, with color enabled. The local time zone is
+pinned to ` + demoLocalTimezone + ` and the config file is neither read nor written, so the output is the same on every
+machine. This is useful for keeping screenshots and documentation up to date.
+
+Examples:
+
+  # Print the demo table:
+  $ timeBuddy demo`,
+	Args: cobra.NoArgs,
+	// Override the root command's PersistentPreRunE so settings in the config file don't change the demo
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		loc, err := time.LoadLocation(demoLocalTimezone)
+		if err != nil {
+			l.Fatal().Str("timezone", demoLocalTimezone).Err(err).Send()
+		}
+		time.Local = loc
+
+		date = demoDate
+		timeOfDay = demoTime
+		specifiedHour, specifiedMinute, err = parseTimeString(demoTime)
+		if err != nil {
+			l.Fatal().Str("time", demoTime).Err(err).Send()
+		}
+		workingHours = demoWorkingHours
+
+		var zones timezoneDetails
+		for i, z := range demoTimezones {
+			zone, err := getZoneInfo(cmd.Context(), z, date, l)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			zone.index = i + 1
+			zones = append(zones, zone)
+		}
+		printTimeTable(os.Stdout, zones, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+}
@@ -0,0 +1,307 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffDate      string
+	diffOutput    string
+	diffDate1     string
+	diffDate2     string
+	diffTimezones []string
+	diffColor     bool
+)
+
+// zoneOffset describes the UTC offset of a timezone at a specific point in time.
+type zoneOffset struct {
+	Timezone      string `json:"timezone"`
+	Abbreviation  string `json:"abbreviation"`
+	OffsetSeconds int    `json:"offset_seconds"`
+	DST           bool   `json:"dst"`
+}
+
+// offsetDiff is the difference between the offsets of two timezones at a specific point in time.
+type offsetDiff struct {
+	From        zoneOffset `json:"from"`
+	To          zoneOffset `json:"to"`
+	Time        string     `json:"time"`
+	Difference  string     `json:"difference"`
+	DiffSeconds int        `json:"difference_seconds"`
+}
+
+// getZoneOffset returns the offset details for the timezone at time t. The timezone is resolved the same way as the
+// table's, with getZoneInfo.
+func getZoneOffset(timezone string, t time.Time) (zoneOffset, error) {
+	z, err := getZoneInfo(context.Background(), timezone, t.UTC().Format(time.DateOnly), l)
+	if err != nil {
+		return zoneOffset{}, err
+	}
+	lt := t.In(z.currentTime.Location())
+	abbreviation, offset := lt.Zone()
+	return zoneOffset{
+		Timezone:      z.name,
+		Abbreviation:  abbreviation,
+		OffsetSeconds: offset,
+		DST:           lt.IsDST(),
+	}, nil
+}
+
+// formatOffsetDiff formats a difference in seconds as a signed hours and minutes string, i.e. +15:00 or -9:30.
+func formatOffsetDiff(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// getOffsetDiff returns how far ahead the "to" timezone is from the "from" timezone at time t.
+func getOffsetDiff(from, to string, t time.Time) (offsetDiff, error) {
+	f, err := getZoneOffset(from, t)
+	if err != nil {
+		return offsetDiff{}, err
+	}
+	o, err := getZoneOffset(to, t)
+	if err != nil {
+		return offsetDiff{}, err
+	}
+	diff := o.OffsetSeconds - f.OffsetSeconds
+	return offsetDiff{
+		From:        f,
+		To:          o,
+		Time:        t.UTC().Format(time.RFC3339),
+		Difference:  formatOffsetDiff(diff),
+		DiffSeconds: diff,
+	}, nil
+}
+
+// dateOffsetDiff is the change in a timezone's UTC offset between two dates.
+type dateOffsetDiff struct {
+	Timezone     string `json:"timezone"`
+	Offset1      int    `json:"offset1_minutes"`
+	Offset2      int    `json:"offset2_minutes"`
+	DeltaMinutes int    `json:"delta_minutes"`
+}
+
+// diffDateTime returns the time a date is evaluated at by diff, noon UTC, so the offset isn't taken from the hours a
+// change happens in.
+func diffDateTime(date string) (time.Time, error) {
+	d, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, time.UTC), nil
+}
+
+// dateOffsets returns the offset details of the timezone on each of two YYYY-MM-DD dates, evaluated at diffDateTime.
+func dateOffsets(tz string, date1, date2 string) (zoneOffset, zoneOffset, error) {
+	t1, err := diffDateTime(date1)
+	if err != nil {
+		return zoneOffset{}, zoneOffset{}, err
+	}
+	t2, err := diffDateTime(date2)
+	if err != nil {
+		return zoneOffset{}, zoneOffset{}, err
+	}
+	o1, err := getZoneOffset(tz, t1)
+	if err != nil {
+		return zoneOffset{}, zoneOffset{}, err
+	}
+	o2, err := getZoneOffset(tz, t2)
+	if err != nil {
+		return zoneOffset{}, zoneOffset{}, err
+	}
+	return o1, o2, nil
+}
+
+// computeOffsetDiff returns the UTC offset of the timezone on each of two YYYY-MM-DD dates, and how much it changed
+// from the first to the second, all in minutes.
+func computeOffsetDiff(tz string, date1, date2 string) (int, int, int, error) {
+	o1, o2, err := dateOffsets(tz, date1, date2)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	offset1, offset2 := o1.OffsetSeconds/60, o2.OffsetSeconds/60
+	return offset1, offset2, offset2 - offset1, nil
+}
+
+// resolveDiffDate resolves a date given to diff, which accepts the same values as timeBuddy --date, to YYYY-MM-DD.
+func resolveDiffDate(name, value string) string {
+	resolved, err := resolveRelativeDate(value)
+	if err != nil {
+		l.Fatal().Str(name, value).Err(err).Send()
+	}
+	if _, err := time.Parse(time.DateOnly, resolved); err != nil {
+		l.Fatal().Str(name, value).Err(err).Send()
+	}
+	return resolved
+}
+
+// runDateDiff prints the offset of each timezone on two dates, and how much it changed.
+func runDateDiff(timezones []string) {
+	date1 := resolveDiffDate("date1", diffDate1)
+	date2 := resolveDiffDate("date2", diffDate2)
+	diffs := make([]dateOffsetDiff, 0, len(timezones))
+	for _, tz := range deduplicateSlice(timezones) {
+		o1, o2, err := dateOffsets(tz, date1, date2)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		offset1, offset2 := o1.OffsetSeconds/60, o2.OffsetSeconds/60
+		diffs = append(diffs, dateOffsetDiff{Timezone: o1.Timezone, Offset1: offset1, Offset2: offset2, DeltaMinutes: offset2 - offset1})
+	}
+
+	switch diffOutput {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diffs); err != nil {
+			l.Fatal().Err(err).Send()
+		}
+	case "text":
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		t.Style().Format.Header = text.FormatDefault
+		t.AppendHeader(table.Row{"Timezone", date1, date2, "Delta"})
+		for _, d := range diffs {
+			row := table.Row{d.Timezone, "UTC" + formatOffsetDiff(d.Offset1*60), "UTC" + formatOffsetDiff(d.Offset2*60), formatOffsetDiff(d.DeltaMinutes * 60)}
+			// a changed offset moves recurring meetings, so it stands out with --color
+			if d.DeltaMinutes != 0 && diffColor {
+				for i := range row {
+					row[i] = text.Colors{text.FgHiYellow, text.Bold}.Sprint(row[i])
+				}
+			}
+			t.AppendRow(row)
+		}
+		t.Render()
+	default:
+		l.Fatal().Str("output", diffOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+	}
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <from timezone> <to timezone>",
+	Short: "Show the offset difference between two time zones",
+	Long: `Show how many hours and minutes the second time zone is ahead of, or behind, the first time zone. Whether each time
+zone is currently observing Daylight Saving Time is also shown.
+
+The difference is calculated for the current time unless a date is provided with --date, which is useful for checking
+the difference after an upcoming Daylight Saving Time change. Dates are evaluated at noon UTC.
+
+With --date1 and --date2, each time zone given as an argument or with --timezone is instead compared with itself: its
+UTC offset on each date is shown, with how much it changed, i.e. to see whether a recurring meeting will shift by an
+hour. Changed offsets are highlighted with --color.
+
+Examples:
+
+  # Show how far ahead Sydney is from Chicago right now:
+  $ timeBuddy diff America/Chicago Australia/Sydney
+
+  # Show the difference after the next Daylight Saving Time change:
+  $ timeBuddy diff America/Chicago Australia/Sydney --date 2024-11-05
+
+  # Output the difference as JSON:
+  $ timeBuddy diff America/Chicago Australia/Sydney --output json
+
+  # Compare the offsets of New York and London in winter and summer:
+  $ timeBuddy diff --date1 2025-01-15 --date2 2025-06-15 --timezone America/New_York --timezone Europe/London`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("date1") && !cmd.Flags().Changed("date2") {
+			if len(diffTimezones) > 0 {
+				return fmt.Errorf("--timezone can only be used with --date1 and --date2")
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		}
+		if !cmd.Flags().Changed("date1") || !cmd.Flags().Changed("date2") {
+			return fmt.Errorf("--date1 and --date2 must be used together")
+		}
+		if cmd.Flags().Changed("date") {
+			return fmt.Errorf("--date can't be used with --date1 and --date2")
+		}
+		if len(args)+len(diffTimezones) == 0 {
+			return fmt.Errorf("requires at least one time zone, as an argument or with --timezone")
+		}
+		return nil
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= 2 && !cmd.Flags().Changed("date1") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeTimezone(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.Flags().Changed("date1") {
+			runDateDiff(append(args, diffTimezones...))
+			return
+		}
+
+		t := time.Now()
+		if cmd.Flags().Changed("date") {
+			resolved, err := resolveRelativeDate(diffDate)
+			if err != nil {
+				l.Fatal().Str("date", diffDate).Err(err).Send()
+			}
+			d, err := time.Parse(time.DateOnly, resolved)
+			if err != nil {
+				l.Fatal().Str("date", diffDate).Err(err).Send()
+			}
+			t = time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, time.UTC)
+		}
+
+		diff, err := getOffsetDiff(args[0], args[1], t)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+
+		switch diffOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(diff); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			fmt.Printf("%s is %s from %s\n", diff.To.Timezone, diff.Difference, diff.From.Timezone)
+			for _, z := range []zoneOffset{diff.From, diff.To} {
+				dst := "not observing DST"
+				if z.DST {
+					dst = "observing DST"
+				}
+				fmt.Printf("  %s [%s,%s] is %s\n", z.Timezone, z.Abbreviation, formatOffsetDiff(z.OffsetSeconds), dst)
+			}
+		default:
+			l.Fatal().Str("output", diffOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&diffDate, "date", "d", "", "``date to calculate the difference for. Accepts the same values as timeBuddy --date. Defaults to now.")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "text", "``output format, text or json")
+	diffCmd.Flags().StringVar(&diffDate1, "date1", "", "``first date to compare each time zone's offset on, with --date2. Accepts the same values as timeBuddy --date.")
+	diffCmd.Flags().StringVar(&diffDate2, "date2", "", "``second date to compare each time zone's offset on, with --date1")
+	diffCmd.Flags().StringArrayVarP(&diffTimezones, "timezone", "z", []string{}, "``time zone to compare with --date1 and --date2. Can be used multiple times.")
+	// the time zones to compare are always given explicitly, so the timezones in the config file aren't a default
+	if err := diffCmd.Flags().SetAnnotation("timezone", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	diffCmd.Flags().BoolVarP(&diffColor, "color", "c", false, "highlight time zones whose offset changed between --date1 and --date2")
+	if err := diffCmd.RegisterFlagCompletionFunc("timezone", completeTimezone); err != nil {
+		l.Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_getOffsetDiff(t *testing.T) {
+	march := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		from, to    string
+		t           time.Time
+		want        string
+		wantFromDST bool
+		wantToDST   bool
+	}{
+		{name: "same offset", from: "UTC", to: "Africa/Abidjan", t: june, want: "+0:00"},
+		{name: "same offset, both observing DST", from: "Europe/Paris", to: "Europe/Berlin", t: june, want: "+0:00", wantFromDST: true, wantToDST: true},
+		{name: "half hour offset ahead", from: "Europe/London", to: "Asia/Kolkata", t: june, want: "+4:30", wantFromDST: true},
+		{name: "half hour offset behind", from: "Asia/Kolkata", to: "Europe/London", t: june, want: "-4:30", wantToDST: true},
+		{name: "45 minute offset", from: "Asia/Kolkata", to: "Asia/Kathmandu", t: june, want: "+0:15"},
+		// New York moves to DST on 2024-03-10, London not until 2024-03-31
+		{name: "between DST changes", from: "America/New_York", to: "Europe/London", t: march, want: "+4:00", wantFromDST: true},
+		{name: "after both DST changes", from: "America/New_York", to: "Europe/London", t: june, want: "+5:00", wantFromDST: true, wantToDST: true},
+		// Sydney observes DST in the southern summer, so the difference from Chicago changes by two hours over the year
+		{name: "opposite hemispheres in winter", from: "America/Chicago", to: "Australia/Sydney", t: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), want: "+17:00", wantToDST: true},
+		{name: "opposite hemispheres in summer", from: "America/Chicago", to: "Australia/Sydney", t: june, want: "+15:00", wantFromDST: true},
+		{name: "city names", from: "chicago", to: "sydney", t: june, want: "+15:00", wantFromDST: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getOffsetDiff(tt.from, tt.to, tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Difference != tt.want {
+				t.Errorf("getOffsetDiff() difference = %s, want %s", got.Difference, tt.want)
+			}
+			if got.From.DST != tt.wantFromDST || got.To.DST != tt.wantToDST {
+				t.Errorf("getOffsetDiff() DST = %v, %v, want %v, %v", got.From.DST, got.To.DST, tt.wantFromDST, tt.wantToDST)
+			}
+			if got.DiffSeconds != got.To.OffsetSeconds-got.From.OffsetSeconds {
+				t.Errorf("getOffsetDiff() difference_seconds = %d, want %d", got.DiffSeconds, got.To.OffsetSeconds-got.From.OffsetSeconds)
+			}
+		})
+	}
+
+	if _, err := getOffsetDiff("UTC", "Bogus/Zone", june); err == nil {
+		t.Errorf("getOffsetDiff() with an invalid timezone succeeded")
+	}
+}
+
+func Test_computeOffsetDiff(t *testing.T) {
+	tests := []struct {
+		name         string
+		tz           string
+		date1, date2 string
+		wantOffset1  int
+		wantOffset2  int
+		wantDelta    int
+		wantErr      bool
+	}{
+		{name: "no DST", tz: "Asia/Tokyo", date1: "2025-01-15", date2: "2025-06-15", wantOffset1: 9 * 60, wantOffset2: 9 * 60},
+		{name: "half hour offset without DST", tz: "Asia/Kolkata", date1: "2025-01-15", date2: "2025-06-15", wantOffset1: 5*60 + 30, wantOffset2: 5*60 + 30},
+		{name: "spring forward", tz: "America/New_York", date1: "2025-01-15", date2: "2025-06-15", wantOffset1: -5 * 60, wantOffset2: -4 * 60, wantDelta: 60},
+		{name: "fall back", tz: "America/New_York", date1: "2025-06-15", date2: "2025-12-15", wantOffset1: -4 * 60, wantOffset2: -5 * 60, wantDelta: -60},
+		{name: "half hour offset with DST", tz: "Australia/Adelaide", date1: "2025-01-15", date2: "2025-06-15", wantOffset1: 10*60 + 30, wantOffset2: 9*60 + 30, wantDelta: -60},
+		// Lord Howe only moves by half an hour
+		{name: "half hour DST", tz: "Australia/Lord_Howe", date1: "2025-01-15", date2: "2025-06-15", wantOffset1: 11 * 60, wantOffset2: 10*60 + 30, wantDelta: -30},
+		// noon UTC on 2025-03-09 is after the change at 07:00 UTC
+		{name: "day of the change", tz: "America/New_York", date1: "2025-03-08", date2: "2025-03-09", wantOffset1: -5 * 60, wantOffset2: -4 * 60, wantDelta: 60},
+		{name: "invalid date", tz: "UTC", date1: "2025-13-01", date2: "2025-06-15", wantErr: true},
+		{name: "invalid timezone", tz: "Bogus/Zone", date1: "2025-01-15", date2: "2025-06-15", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset1, offset2, delta, err := computeOffsetDiff(tt.tz, tt.date1, tt.date2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("computeOffsetDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if offset1 != tt.wantOffset1 || offset2 != tt.wantOffset2 || delta != tt.wantDelta {
+				t.Errorf("computeOffsetDiff() = %d, %d, %d, want %d, %d, %d", offset1, offset2, delta, tt.wantOffset1, tt.wantOffset2, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func Test_diffCmd(t *testing.T) {
+	useTestConfig(t, "")
+	out := executeCommand(t, "diff", "America/New_York", "Europe/London", "--date", "2024-03-15")
+	for _, want := range []string{
+		"Europe/London is +4:00 from America/New_York",
+		"America/New_York [EDT,-4:00] is observing DST",
+		"Europe/London [GMT,+0:00] is not observing DST",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff output doesn't contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func Test_diffCmd_dates(t *testing.T) {
+	useTestConfig(t, "")
+	out := executeCommand(t, "diff", "--date1", "2025-01-15", "--date2", "2025-06-15", "-z", "Asia/Kolkata", "-z", "America/New_York", "--output", "json")
+	var got []dateOffsetDiff
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("diff output isn't JSON: %v\n%s", err, out)
+	}
+	want := []dateOffsetDiff{
+		{Timezone: "Asia/Kolkata", Offset1: 330, Offset2: 330},
+		{Timezone: "America/New_York", Offset1: -300, Offset2: -240, DeltaMinutes: 60},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("diff = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diff[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// doctorStatus is the result of a doctor check. Warnings don't make doctor fail.
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is the result of a single doctor check, with a hint on how to fix it if it didn't pass.
+type doctorCheck struct {
+	name   string
+	status doctorStatus
+	detail string
+	hint   string
+}
+
+// knownConfigKeys returns the top level keys timeBuddy reads from the config file: the keys managed by the config
+// subcommand, the maps managed by other subcommands, and the root command's flags, which are filled from keys of the
+// same name, or of their old names and legacy spellings.
+func knownConfigKeys() []string {
+	keys := append(append([]string{"aliases", "groups", "presets", "profiles", "recently_used", "row_colors", "schema_version", "style"}, configKeys...), configMapKeys...)
+	rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		keys = append(keys, f.Name)
+	})
+	for _, names := range []map[string]string{renamedFlags, legacyFlagAliases} {
+		for oldName := range names {
+			keys = append(keys, oldName)
+		}
+	}
+	return keys
+}
+
+// configuredTimezoneRefs returns every timezone referenced in the config file, keyed by where it was found, i.e.
+// groups.emea.
+func configuredTimezoneRefs(fv *viper.Viper) map[string][]string {
+	refs := map[string][]string{"timezone": fv.GetStringSlice("timezone")}
+	for _, key := range []string{"groups", "profiles"} {
+		for name := range fv.GetStringMap(key) {
+			refs[key+"."+name] = fv.GetStringSlice(key + "." + name)
+		}
+	}
+	for name := range fv.GetStringMap("presets") {
+		refs["presets."+name] = fv.GetStringSlice("presets." + name + ".timezone")
+	}
+	for alias, tz := range aliasTimezones(fv.GetStringMap("aliases")) {
+		refs["aliases."+alias] = []string{tz}
+	}
+	return refs
+}
+
+// checkConfigFile checks that the config file exists, parses, and only has known keys. It returns the timezones
+// referenced in the file, for checkConfiguredTimezones.
+func checkConfigFile() ([]doctorCheck, map[string][]string) {
+	path := getConfigPath()
+	if _, err := os.Stat(path); err != nil {
+		hint := "run timeBuddy once to create it, or check that " + getConfigDir() + " exists"
+		if _, legacyErr := os.Stat(legacyConfigPath()); legacyErr == nil && configFileOverride() == "" {
+			hint = "run timeBuddy once to move " + legacyConfigPath() + " there"
+		}
+		return []doctorCheck{{
+			name:   "config file exists",
+			status: doctorFail,
+			detail: err.Error(),
+			hint:   hint,
+		}}, nil
+	}
+	checks := []doctorCheck{{name: "config file exists", status: doctorPass, detail: path}}
+
+	fv, err := readConfigFile()
+	if err != nil {
+		return append(checks, doctorCheck{
+			name:   "config file parses",
+			status: doctorFail,
+			detail: err.Error(),
+			hint:   "fix the YAML syntax in " + path + ", or move it aside to start over",
+		}), nil
+	}
+	checks = append(checks, doctorCheck{name: "config file parses", status: doctorPass})
+
+	known := knownConfigKeys()
+	var unknown []string
+	for key := range fv.AllSettings() {
+		if !slices.Contains(known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	if len(unknown) > 0 {
+		checks = append(checks, doctorCheck{
+			name:   "config file has only known keys",
+			status: doctorFail,
+			detail: "unknown keys: " + strings.Join(unknown, ", "),
+			hint:   "remove them with timeBuddy config unset <key>, they may be misspelled",
+		})
+	} else {
+		checks = append(checks, doctorCheck{name: "config file has only known keys", status: doctorPass})
+	}
+	return checks, configuredTimezoneRefs(fv)
+}
+
+// invalidTimezoneRefs returns each timezone in refs that doesn't load, as "<timezone> in <key>", sorted.
+func invalidTimezoneRefs(refs map[string][]string) []string {
+	var invalid []string
+	for key, tzs := range refs {
+		for _, tz := range tzs {
+			if _, err := loadLocation(tz); err != nil {
+				// timezone lists may hold aliases
+				if _, err := loadLocation(resolveAliasFrom(refs, tz)); err != nil {
+					invalid = append(invalid, fmt.Sprintf("%s in %s", tz, key))
+				}
+			}
+		}
+	}
+	sort.Strings(invalid)
+	return invalid
+}
+
+// checkConfiguredTimezones checks that every timezone referenced in the config file loads.
+func checkConfiguredTimezones(refs map[string][]string) doctorCheck {
+	check := doctorCheck{name: "configured timezones load", status: doctorPass}
+	if invalid := invalidTimezoneRefs(refs); len(invalid) > 0 {
+		check.status = doctorFail
+		check.detail = strings.Join(invalid, ", ")
+		check.hint = "use timeBuddy search to find the correct name, then update the config file"
+	}
+	return check
+}
+
+// resolveAliasFrom returns the timezone an alias refers to, using the aliases in refs, or the name unchanged.
+func resolveAliasFrom(refs map[string][]string, name string) string {
+	if tzs, ok := refs["aliases."+strings.ToLower(name)]; ok && len(tzs) > 0 {
+		return tzs[0]
+	}
+	return name
+}
+
+// checkLocalTimezone checks that the local timezone can be resolved.
+func checkLocalTimezone() doctorCheck {
+	loc, err := time.LoadLocation("Local")
+	if err != nil {
+		return doctorCheck{
+			name:   "local timezone resolves",
+			status: doctorFail,
+			detail: err.Error(),
+			hint:   "set the TZ environment variable, i.e. TZ=Europe/London, or install tzdata",
+		}
+	}
+	name, _ := time.Now().In(loc).Zone()
+	return doctorCheck{name: "local timezone resolves", status: doctorPass, detail: name}
+}
+
+// checkConfigDirWritable checks that a file can be created in the config directory.
+func checkConfigDirWritable() doctorCheck {
+	dir := getConfigDir()
+	f, err := os.CreateTemp(dir, ".timeBuddy-doctor-*")
+	if err != nil {
+		return doctorCheck{
+			name:   "config directory is writable",
+			status: doctorFail,
+			detail: err.Error(),
+			hint:   "create " + dir + " and make sure your user can write to it",
+		}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return doctorCheck{name: "config directory is writable", status: doctorPass, detail: dir}
+}
+
+// checkColorSupport checks whether the terminal is likely to show --color output. It only warns, since the table
+// works without color.
+func checkColorSupport() doctorCheck {
+	check := doctorCheck{name: "terminal supports color", status: doctorPass, detail: "TERM=" + os.Getenv("TERM")}
+	fi, err := os.Stdout.Stat()
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		check.status, check.detail, check.hint = doctorWarn, "NO_COLOR is set", "unset NO_COLOR to use --color"
+	case os.Getenv("TERM") == "dumb" || os.Getenv("TERM") == "":
+		check.status, check.hint = doctorWarn, "set TERM, i.e. TERM=xterm-256color, to use --color"
+	case err != nil || fi.Mode()&os.ModeCharDevice == 0:
+		check.status, check.detail, check.hint = doctorWarn, "output is not a terminal", "colors only show when writing to a terminal"
+	}
+	return check
+}
+
+// printDoctorCheck prints a check as a single line, followed by its hint if it didn't pass.
+func printDoctorCheck(c doctorCheck) {
+	label := map[doctorStatus]string{
+		doctorPass: text.FgGreen.Sprint("PASS"),
+		doctorWarn: text.FgYellow.Sprint("WARN"),
+		doctorFail: text.FgRed.Sprint("FAIL"),
+	}[c.status]
+	line := fmt.Sprintf("[%s] %s", label, c.name)
+	if c.detail != "" {
+		line += ": " + c.detail
+	}
+	fmt.Println(line)
+	if c.status != doctorPass && c.hint != "" {
+		fmt.Printf("       hint: %s\n", c.hint)
+	}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment and config file for problems",
+	Long: `Check that the config file exists, parses, and only has known keys, that every time zone in it loads, that the local
+time zone resolves, that the config directory is writable, and whether the terminal supports color. Each check prints
+PASS, WARN, or FAIL, with a hint on how to fix it. Exits with status 1 if any check fails, so it can be used in setup
+scripts. Unlike other commands, doctor doesn't create the config file.
+
+Examples:
+
+  # Check the environment:
+  $ timeBuddy doctor`,
+	Args: cobra.NoArgs,
+	// Override the root command's PersistentPreRunE so a missing config file isn't created before it's checked
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		checks, refs := checkConfigFile()
+		if refs != nil {
+			checks = append(checks, checkConfiguredTimezones(refs))
+		}
+		checks = append(checks, checkLocalTimezone(), checkConfigDirWritable(), checkColorSupport())
+
+		failed := false
+		for _, c := range checks {
+			printDoctorCheck(c)
+			failed = failed || c.status == doctorFail
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
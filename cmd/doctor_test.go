@@ -0,0 +1,54 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_checkConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// wantUnknown is the detail of the known keys check, or empty if it passes
+		wantUnknown string
+	}{
+		{
+			name:    "current keys",
+			content: "schema_version: 1\ntimezone:\n  - Asia/Tokyo\nno-local: true\ndate-format: dmy\n",
+		},
+		{
+			name:    "renamed and legacy keys",
+			content: "schema_version: 1\ntz:\n  - Asia/Tokyo\nexclude-local: true\n12h: true\n",
+		},
+		{
+			name:        "unknown keys",
+			content:     "schema_version: 1\ntimezones:\n  - Asia/Tokyo\ncolour: true\n",
+			wantUnknown: "unknown keys: colour, timezones",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, tt.content)
+			checks, _ := checkConfigFile()
+			var known *doctorCheck
+			for i := range checks {
+				if checks[i].name == "config file has only known keys" {
+					known = &checks[i]
+				}
+			}
+			if known == nil {
+				t.Fatalf("checkConfigFile() didn't check the keys: %+v", checks)
+			}
+			if tt.wantUnknown == "" {
+				if known.status != doctorPass {
+					t.Errorf("known keys check = %s, want PASS", known.detail)
+				}
+			} else if known.status != doctorFail || !strings.Contains(known.detail, tt.wantUnknown) {
+				t.Errorf("known keys check = %v %q, want FAIL %q", known.status, known.detail, tt.wantUnknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dstOutput    string
+	dstPast      bool
+	dstTimezones []string
+	dstWithin    string
+)
+
+// dstTransition is a change in a timezone's UTC offset, i.e. the start or end of daylight saving time.
+type dstTransition struct {
+	Local              string `json:"local"`
+	UTC                string `json:"utc"`
+	BeforeAbbreviation string `json:"before_abbreviation"`
+	BeforeOffset       string `json:"before_offset"`
+	AfterAbbreviation  string `json:"after_abbreviation"`
+	AfterOffset        string `json:"after_offset"`
+}
+
+// dstZone is the nearest offset changes in a timezone. Next and Previous are nil when there is no change within the
+// search window.
+type dstZone struct {
+	Timezone string         `json:"timezone"`
+	Previous *dstTransition `json:"previous,omitempty"`
+	Next     *dstTransition `json:"next"`
+}
+
+// parseDayDuration parses a duration that may be given in days, i.e. 90d, in addition to the units accepted by
+// time.ParseDuration.
+func parseDayDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q, expected a positive number of days like 90d", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected a positive duration like 90d or 72h", s)
+	}
+	return d, nil
+}
+
+// newDSTTransition returns the transition that happens at instant t in the location.
+func newDSTTransition(t time.Time, loc *time.Location) dstTransition {
+	before := t.Add(-time.Second).In(loc)
+	after := t.In(loc)
+	beforeAbbreviation, _ := before.Zone()
+	afterAbbreviation, _ := after.Zone()
+	return dstTransition{
+		Local:              after.Format("Mon, Jan 2 2006 3:04PM MST"),
+		UTC:                t.UTC().Format("2006-01-02 15:04 MST"),
+		BeforeAbbreviation: beforeAbbreviation,
+		BeforeOffset:       before.Format("-07:00"),
+		AfterAbbreviation:  afterAbbreviation,
+		AfterOffset:        after.Format("-07:00"),
+	}
+}
+
+// dstBoundary returns the instant of the first change in the location's UTC offset after from, or the last one before
+// it if forward is false. Zone boundaries where only the abbreviation changes are skipped. ok is false if there is no
+// change within the window.
+func dstBoundary(loc *time.Location, from time.Time, window time.Duration, forward bool) (boundary time.Time, ok bool) {
+	t := from.In(loc)
+	for {
+		start, end := t.ZoneBounds()
+		boundary = end
+		if !forward {
+			boundary = start
+		}
+		// a zero boundary means the zone has no further transitions in that direction
+		if boundary.IsZero() || (forward && boundary.Sub(from) > window) || (!forward && from.Sub(boundary) > window) {
+			return time.Time{}, false
+		}
+		_, before := boundary.Add(-time.Second).In(loc).Zone()
+		_, after := boundary.In(loc).Zone()
+		if before != after {
+			return boundary, true
+		}
+		if forward {
+			t = boundary
+		} else {
+			t = boundary.Add(-time.Second)
+		}
+	}
+}
+
+// findDSTTransition returns the first change in the location's UTC offset after from, or the last one before it if
+// forward is false. ok is false if there is no change within the window. See dstBoundary.
+func findDSTTransition(loc *time.Location, from time.Time, window time.Duration, forward bool) (transition dstTransition, ok bool) {
+	boundary, ok := dstBoundary(loc, from, window, forward)
+	if !ok {
+		return dstTransition{}, false
+	}
+	return newDSTTransition(boundary, loc), true
+}
+
+// formatDSTTransition returns the change in a transition as a string, i.e. "EST -05:00 → EDT -04:00".
+func formatDSTTransition(tr dstTransition) string {
+	return fmt.Sprintf("%s %s → %s %s", tr.BeforeAbbreviation, tr.BeforeOffset, tr.AfterAbbreviation, tr.AfterOffset)
+}
+
+// printDSTTable prints the transitions as a table, one row per timezone and direction.
+func printDSTTable(zones []dstZone) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.Style().Title.Align = text.AlignCenter
+	t.Style().Format.Header = text.FormatDefault
+	t.SetTitle("Daylight Saving Time Transitions")
+	t.AppendHeader(table.Row{"Timezone", "Transition", "Local Time", "UTC", "Change"})
+
+	appendRow := func(name, direction string, tr *dstTransition) {
+		if tr == nil {
+			t.AppendRow(table.Row{name, direction, "none", "", ""})
+			return
+		}
+		t.AppendRow(table.Row{name, direction, tr.Local, tr.UTC, formatDSTTransition(*tr)})
+	}
+	for _, z := range zones {
+		if dstPast {
+			appendRow(z.Timezone, "previous", z.Previous)
+		}
+		appendRow(z.Timezone, "next", z.Next)
+	}
+	t.Render()
+}
+
+var dstCmd = &cobra.Command{
+	Use:   "dst",
+	Short: "List upcoming daylight saving time transitions",
+	Long: `List the next change in UTC offset, i.e. the start or end of daylight saving time, for each of the configured time
+zones. The transition is shown in both the time zone's local time and UTC, along with the offset and abbreviation before
+and after it. Time zones without a transition in the search window show none.
+
+Examples:
+
+  # List the next transition for the time zones in the config file:
+  $ timeBuddy dst
+
+  # Also list the most recent transition, searching 90 days in each direction:
+  $ timeBuddy dst --past --within 90d
+
+  # List the next transition for specific time zones as JSON:
+  $ timeBuddy dst --timezone America/New_York --timezone Asia/Tokyo --output json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		window, err := parseDayDuration(dstWithin)
+		if err != nil {
+			l.Fatal().Str("within", dstWithin).Err(err).Send()
+		}
+		targets := deduplicateSlice(dstTimezones)
+		if len(targets) == 0 {
+			targets = []string{"Local"}
+		}
+
+		now := time.Now()
+		zones := make([]dstZone, 0, len(targets))
+		for _, name := range targets {
+			tz := resolveAlias(name)
+			loc, err := loadLocation(tz)
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			z := dstZone{Timezone: tz}
+			if tr, ok := findDSTTransition(loc, now, window, true); ok {
+				z.Next = &tr
+			}
+			if dstPast {
+				if tr, ok := findDSTTransition(loc, now, window, false); ok {
+					z.Previous = &tr
+				}
+			}
+			zones = append(zones, z)
+		}
+
+		switch dstOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(zones); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "table":
+			printDSTTable(zones)
+		default:
+			l.Fatal().Str("output", dstOutput).Err(fmt.Errorf("invalid output format, expected table or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dstCmd)
+	dstCmd.Flags().StringVarP(&dstOutput, "output", "o", "table", "``output format, table or json")
+	dstCmd.Flags().BoolVarP(&dstPast, "past", "p", false, "also list the most recent transition")
+	dstCmd.Flags().StringArrayVarP(&dstTimezones, "timezone", "z", []string{}, "``timezone to list transitions for. Can be used multiple times. Defaults to the timezones in the config file.")
+	dstCmd.Flags().StringVarP(&dstWithin, "within", "w", "365d", "``how far to search for transitions, in days like 90d or a duration like 72h")
+	err := dstCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
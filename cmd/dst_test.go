@@ -0,0 +1,42 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_findNextDSTTransition(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	window := 366 * 24 * time.Hour
+
+	got, offset, spring, err := findNextDSTTransition(newYork, from, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC); !got.Equal(want) || offset != -4*60 || !spring {
+		t.Errorf("findNextDSTTransition() = %v, %d, %v, want %v, %d, true", got, offset, spring, want, -4*60)
+	}
+	// both DST searches find the same transition
+	if tr, ok := findDSTTransition(newYork, from, window, true); !ok || tr.UTC != got.Format("2006-01-02 15:04 MST") {
+		t.Errorf("findDSTTransition() = %+v, %v, want the transition at %v", tr, ok, got)
+	}
+
+	if _, _, _, err := findNextDSTTransition(newYork, from, 24*time.Hour); !errors.Is(err, errNoDSTTransition) {
+		t.Errorf("findNextDSTTransition() within a day error = %v, want errNoDSTTransition", err)
+	}
+	if _, _, _, err := findNextDSTTransition(tokyo, from, window); !errors.Is(err, errNoDSTTransition) {
+		t.Errorf("findNextDSTTransition() for Asia/Tokyo error = %v, want errNoDSTTransition", err)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	epochFrom      string
+	epochOutput    string
+	epochTimezones []string
+)
+
+// epochTime is the time of an instant in a single timezone.
+type epochTime struct {
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	Time         string `json:"time"`
+}
+
+// epochResult is an instant expressed as a unix timestamp along with its time in each requested timezone.
+type epochResult struct {
+	Epoch int64       `json:"epoch"`
+	Unit  string      `json:"unit"`
+	UTC   string      `json:"utc"`
+	Times []epochTime `json:"times,omitempty"`
+}
+
+// parseEpoch parses a unix timestamp, detecting whether it is in seconds, milliseconds, microseconds, or nanoseconds by
+// its magnitude. Timestamps in seconds have up to 11 digits, which covers dates up to the year 5138.
+func parseEpoch(s string) (time.Time, string, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid unix timestamp %q, expected an integer", s)
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0), "s", nil
+	case abs < 1e14:
+		return time.UnixMilli(n), "ms", nil
+	case abs < 1e17:
+		return time.UnixMicro(n), "us", nil
+	default:
+		return time.Unix(0, n), "ns", nil
+	}
+}
+
+// printEpochResult prints the result as JSON or as a list of times, one per timezone.
+func printEpochResult(result epochResult) {
+	switch epochOutput {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			l.Fatal().Err(err).Send()
+		}
+	case "text":
+		if len(result.Times) == 0 {
+			fmt.Println(result.Epoch)
+			return
+		}
+		width := 0
+		for _, c := range result.Times {
+			width = max(width, len(c.Timezone))
+		}
+		for _, c := range result.Times {
+			fmt.Printf("%-*s  %s %s\n", width, c.Timezone, c.Time, c.Abbreviation)
+		}
+	default:
+		l.Fatal().Str("output", epochOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+	}
+}
+
+var epochCmd = &cobra.Command{
+	Use:   "epoch [timestamp]",
+	Short: "Convert between unix timestamps and time zones",
+	Long: `Convert a unix timestamp to the time in each of the configured time zones, or convert a date and time to a unix
+timestamp with --from.
+
+Timestamps in seconds, milliseconds, microseconds, and nanoseconds are detected automatically by their magnitude. With
+--from, the time must be in the format 'YYYY-MM-DD HH:MM' or 'HH:MM', in the time zone given by --timezone, which
+defaults to your local time zone.
+
+Examples:
+
+  # Show a timestamp in the configured time zones:
+  $ timeBuddy epoch 1718462400
+
+  # Show a timestamp in milliseconds in specific time zones:
+  $ timeBuddy epoch 1718462400000 --timezone America/New_York --timezone Asia/Tokyo
+
+  # Convert a date and time in UTC to a timestamp:
+  $ timeBuddy epoch --from "2024-06-15 15:00" --timezone UTC`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("from") {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.Flags().Changed("from") {
+			tz := "Local"
+			if cmd.Flags().Changed("timezone") {
+				if len(epochTimezones) != 1 {
+					l.Fatal().Strs("timezone", epochTimezones).Err(fmt.Errorf("--from requires a single --timezone")).Send()
+				}
+				tz = epochTimezones[0]
+			}
+			loc, err := loadLocation(resolveAlias(tz))
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			t, err := parseDateTime(epochFrom, loc)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			printEpochResult(epochResult{Epoch: t.Unix(), Unit: "s", UTC: t.UTC().Format(time.RFC3339)})
+			return
+		}
+
+		t, unit, err := parseEpoch(args[0])
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		targets := deduplicateSlice(epochTimezones)
+		if len(targets) == 0 {
+			targets = []string{"Local"}
+		}
+		times := make([]epochTime, 0, len(targets))
+		for _, name := range targets {
+			tz := resolveAlias(name)
+			loc, err := loadLocation(tz)
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			lt := t.In(loc)
+			abbreviation, _ := lt.Zone()
+			times = append(times, epochTime{Timezone: tz, Abbreviation: abbreviation, Time: lt.Format(time.DateTime)})
+		}
+		n, _ := strconv.ParseInt(strings.TrimSpace(args[0]), 10, 64)
+		printEpochResult(epochResult{Epoch: n, Unit: unit, UTC: t.UTC().Format(time.RFC3339Nano), Times: times})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(epochCmd)
+	epochCmd.Flags().StringVarP(&epochFrom, "from", "f", "", "``date and time to convert to a unix timestamp, in the format 'YYYY-MM-DD HH:MM' or 'HH:MM'")
+	epochCmd.Flags().StringVarP(&epochOutput, "output", "o", "text", "``output format, text or json")
+	epochCmd.Flags().StringArrayVarP(&epochTimezones, "timezone", "z", []string{}, "``timezone to show the timestamp in, or the timezone of --from. Can be used multiple times. Defaults to the timezones in the config file.")
+	err := epochCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
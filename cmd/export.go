@@ -0,0 +1,67 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var exportOutput string
+
+// exportExcludedKeys are config keys that only make sense on the machine they were recorded on
+var exportExcludedKeys = []string{"recently_used"}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the settings in the config file for use with import",
+	Long: `Print the settings in the config file, including time zones, labels, aliases, groups, and profiles, to stdout as YAML
+or JSON. Load them on another machine with timeBuddy import. Recently used time zones aren't exported.
+
+Examples:
+
+  # Copy the settings to another machine:
+  $ timeBuddy export > timeBuddy.yaml
+  $ timeBuddy import timeBuddy.yaml
+
+  # Print the settings as JSON:
+  $ timeBuddy export --output json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		settings := fv.AllSettings()
+		for _, key := range exportExcludedKeys {
+			delete(settings, key)
+		}
+
+		switch exportOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(settings); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "yaml":
+			content, err := yaml.Marshal(settings)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			fmt.Print(string(content))
+		default:
+			l.Fatal().Str("output", exportOutput).Err(fmt.Errorf("invalid output format, expected yaml or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "yaml", "``output format, yaml or json")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+// abbreviationToFlag maps timezone abbreviations to the flag emoji of the country they are most commonly used in.
+// Abbreviations shared by several countries, like CET, map to the country with the largest population using them.
+// Numeric abbreviations, like +0545, have no entry.
+var abbreviationToFlag = map[string]string{
+	"ACDT": "🇦🇺",
+	"ACST": "🇦🇺",
+	"AEDT": "🇦🇺",
+	"AEST": "🇦🇺",
+	"AKDT": "🇺🇸",
+	"AKST": "🇺🇸",
+	"AWST": "🇦🇺",
+	"BST":  "🇬🇧",
+	"CAT":  "🇿🇦",
+	"CDT":  "🇺🇸",
+	"CEST": "🇩🇪",
+	"CET":  "🇩🇪",
+	"CST":  "🇺🇸",
+	"EAT":  "🇰🇪",
+	"EDT":  "🇺🇸",
+	"EEST": "🇬🇷",
+	"EET":  "🇬🇷",
+	"EST":  "🇺🇸",
+	"GMT":  "🇬🇧",
+	"HDT":  "🇺🇸",
+	"HKT":  "🇭🇰",
+	"HST":  "🇺🇸",
+	"IDT":  "🇮🇱",
+	"IST":  "🇮🇳",
+	"JST":  "🇯🇵",
+	"KST":  "🇰🇷",
+	"MDT":  "🇺🇸",
+	"MSK":  "🇷🇺",
+	"MST":  "🇺🇸",
+	"NDT":  "🇨🇦",
+	"NST":  "🇨🇦",
+	"NZDT": "🇳🇿",
+	"NZST": "🇳🇿",
+	"PDT":  "🇺🇸",
+	"PHT":  "🇵🇭",
+	"PKT":  "🇵🇰",
+	"PST":  "🇺🇸",
+	"SAST": "🇿🇦",
+	"SGT":  "🇸🇬",
+	"WAT":  "🇳🇬",
+	"WEST": "🇵🇹",
+	"WET":  "🇵🇹",
+	"WIB":  "🇮🇩",
+}
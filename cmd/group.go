@@ -0,0 +1,221 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	groupFormat    string
+	groupTimezones []string
+)
+
+// timezoneGroup is a named list of timezones saved in the config file.
+type timezoneGroup struct {
+	Name      string   `json:"name"`
+	Timezones []string `json:"timezones"`
+}
+
+// configuredGroups returns the groups saved in the config file, sorted by name. Viper lowercases map keys, so group
+// names are case-insensitive.
+func configuredGroups() []timezoneGroup {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	var groups []timezoneGroup
+	for name := range fv.GetStringMap("groups") {
+		groups = append(groups, timezoneGroup{Name: name, Timezones: fv.GetStringSlice("groups." + name)})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// getGroup returns the timezones in a group, exiting if the group doesn't exist.
+func getGroup(name string) []string {
+	for _, g := range configuredGroups() {
+		if g.Name == strings.ToLower(name) {
+			return g.Timezones
+		}
+	}
+	l.Fatal().Str("group", name).Err(fmt.Errorf("group is not defined")).Send()
+	return nil
+}
+
+// completeGroups completes the names of the groups saved in the config file.
+func completeGroups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, g := range configuredGroups() {
+		names = append(names, g.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// loadTableSettings sets the table options that are normally set by the root command's flags from the config file, for
+// subcommands that render the table without those flags.
+func loadTableSettings() {
+	colorEnabled = v.GetBool("color")
+	twelveHourEnabled = v.GetBool("twelve-hour")
+	// the project config file takes precedence, as it does for the root command's flags
+	if projectConfig != nil && projectConfig.IsSet("color") {
+		colorEnabled = projectConfig.GetBool("color")
+	}
+	if projectConfig != nil && projectConfig.IsSet("twelve-hour") {
+		twelveHourEnabled = projectConfig.GetBool("twelve-hour")
+	}
+	wh, err := loadWorkingHours(nil)
+	if err != nil {
+		l.Fatal().Err(err).Send()
+	}
+	workingHours = wh
+	markers, err := parseAMPMStyle(v.GetString("ampm-style"))
+	if err != nil {
+		l.Fatal().Str("ampm-style", v.GetString("ampm-style")).Err(err).Send()
+	}
+	ampmMarkers = markers
+}
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage named groups of time zones",
+	Long: `Manage groups, named lists of time zones saved in the config file, i.e. one for each team you work with. A group
+can be shown with group apply without changing the time zones saved for timeBuddy.
+
+Examples:
+
+  # Create a group, then show its table:
+  $ timeBuddy group create emea --timezone Europe/London --timezone Europe/Berlin --timezone Africa/Lagos
+  $ timeBuddy group apply emea
+
+  # List the groups:
+  $ timeBuddy group list`,
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create or replace a group of time zones",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		if strings.ContainsAny(name, "./ ") {
+			l.Fatal().Str("group", args[0]).Err(fmt.Errorf("group name can't contain dots, slashes, or spaces")).Send()
+		}
+		if len(groupTimezones) == 0 {
+			l.Fatal().Str("group", args[0]).Err(fmt.Errorf("at least one --timezone is required")).Send()
+		}
+		for _, tz := range groupTimezones {
+			if _, err := time.LoadLocation(resolveAlias(tz)); err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+		}
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		fv.Set("groups."+name, deduplicateSlice(groupTimezones))
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var groupDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a group",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroups,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		if err := removeConfigKey(fv, "groups."+strings.ToLower(args[0])); err != nil {
+			l.Fatal().Str("group", args[0]).Err(fmt.Errorf("group is not defined")).Send()
+		}
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the groups and how many time zones are in each",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups := configuredGroups()
+		switch groupFormat {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(groups); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "table":
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.Style().Format.Header = text.FormatDefault
+			t.AppendHeader(table.Row{"Group", "Timezones"})
+			for _, g := range groups {
+				t.AppendRow(table.Row{g.Name, len(g.Timezones)})
+			}
+			t.Render()
+		default:
+			l.Fatal().Str("format", groupFormat).Err(fmt.Errorf("invalid format, expected table or json")).Send()
+		}
+	},
+}
+
+var groupShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "List the time zones in a group",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroups,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, tz := range getGroup(args[0]) {
+			fmt.Println(tz)
+		}
+	},
+}
+
+var groupApplyCmd = &cobra.Command{
+	Use:               "apply <name>",
+	Short:             "Show the table for a group",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroups,
+	Run: func(cmd *cobra.Command, args []string) {
+		loadTableSettings()
+		timezones = getGroup(args[0])
+		zones, err := processTimezones(cmd.Context(), timezones, date, l)
+		if err != nil {
+			l.Fatal().Str("group", args[0]).Err(err).Send()
+		}
+		printTimeTable(os.Stdout, zones, colorEnabled)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupCreateCmd, groupDeleteCmd, groupListCmd, groupShowCmd, groupApplyCmd)
+	groupCreateCmd.Flags().StringArrayVarP(&groupTimezones, "timezone", "z", []string{}, "``timezone to include in the group. Can be used multiple times.")
+	if err := groupCreateCmd.Flags().SetAnnotation("timezone", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	err := groupCreateCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+	groupListCmd.Flags().StringVarP(&groupFormat, "format", "f", "table", "``output format, table or json")
+}
@@ -0,0 +1,278 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// highlightTime matches the time of day in a highlight, a 24-hour time like 15 or 9:30, or a 12-hour time like 3pm,
+// 9:30am, or "9 PM"
+const highlightTime = `\d{1,2}(?::\d{2})?(?:\s*[aApP][mM])?`
+
+// maxHighlightDuration is the longest --duration, in minutes, half of the table
+const maxHighlightDuration = 12 * 60
+
+var (
+	highlightSpecs []string
+	// highlightColumns are the UTC hours of the table columns highlighted with --highlight
+	highlightColumns []int
+	// highlightTrailingColumns are the UTC hours of the table columns after the first one of a highlight extended with
+	// --duration
+	highlightTrailingColumns []int
+	// highlightDuration is the length of each highlight in minutes, set with --duration
+	highlightDuration int
+	// highlightInstants are the UTC times highlighted with --highlight that don't fall on the hour, i.e. 09:15 UTC, so
+	// the table can show which part of the highlighted column was meant
+	highlightInstants []string
+	// highlightPattern matches a time, or a range of times like 15-17, followed by the UTC offset or timezone it is
+	// in, or L for the local timezone, i.e. 15+11, 9-5, 9+5:30, 3pm+11, 9:30am-5, 15-17+11, 15@Australia/Sydney,
+	// "15 Australia/Sydney", or 15-17L. 9-5 is the hour 9 at UTC-5, since a range must be followed by an offset,
+	// timezone, or L.
+	highlightPattern = regexp.MustCompile(`^(` + highlightTime + `)(?:-(` + highlightTime + `))?(?:([+-]\d{1,2}(?::?\d{2})?)|(?:@|\s+)(\S+)|([Ll]))$`)
+	// bareHourPattern matches a time without an offset or timezone, which is in the local timezone
+	bareHourPattern = regexp.MustCompile(`^` + highlightTime + `$`)
+	// offsetPattern matches a UTC offset in hours, with optional minutes, i.e. +11, -5, +5:30, or +0545
+	offsetPattern = regexp.MustCompile(`^([+-])(\d{1,2})(?::?(\d{2}))?$`)
+	// offsetSuffixPattern matches the UTC offset at the end of a highlight, so it can be replaced in a suggestion
+	offsetSuffixPattern = regexp.MustCompile(`[+-]\d{1,2}(?::?\d{2})?$`)
+)
+
+// parseOffset parses a UTC offset like +11, -5, +5:30, or +0545 and returns it in minutes east of UTC. An offset like
+// @Australia/Sydney is the offset of that timezone, or alias, on the date.
+func parseOffset(s, date string) (int, error) {
+	if tz, ok := strings.CutPrefix(s, "@"); ok {
+		z, err := getZoneInfo(context.Background(), tz, date, l)
+		if err != nil {
+			return 0, err
+		}
+		return z.offsetMinutes, nil
+	}
+	m := offsetPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid UTC offset %q, expected a format like +11, -5, or +5:30", s)
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes := 0
+	if m[3] != "" {
+		minutes, _ = strconv.Atoi(m[3])
+	}
+	if hours > 14 || minutes > 59 {
+		return 0, fmt.Errorf("invalid UTC offset %q, hours must be 0-14 and minutes 0-59", s)
+	}
+	offset := hours*60 + minutes
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// parseHighlightTime parses the time of day in a highlight and returns it in minutes since midnight. It accepts
+// 24-hour times like 15 or 9:30, and 12-hour times like 3pm or 9:30am, where 12am is midnight and 12pm is noon. The end
+// of a range may also be 24, the end of the day.
+func parseHighlightTime(s string) (int, error) {
+	if s == "24" {
+		return 24 * 60, nil
+	}
+	lower := strings.ToLower(s)
+	// parseTimeString needs minutes on 24-hour times
+	if !strings.Contains(lower, ":") && !strings.HasSuffix(lower, "m") {
+		s += ":00"
+	}
+	hour, minute, err := parseTimeString(s)
+	if err != nil {
+		return 0, err
+	}
+	return hour*60 + minute, nil
+}
+
+// parseHighlightSpec parses a highlight like 15+11, the hour 15:00 in the timezone at UTC+11, or 15@Australia/Sydney,
+// the hour 15:00 in that timezone on the date. The time may also be a 12-hour time like 3pm. A bare time like 15, or
+// 15L, is in the local timezone on the date; use 15+0 for 15:00 UTC. A range like 15-17+11 covers the time from 15:00
+// up to 17:00, and may wrap past midnight, i.e. 23-1+0. It returns the UTC hour of the first table column, the minutes
+// past that hour the highlight starts at, the number of columns, and the offset in minutes. The first column is always
+// the one holding the start, so 15:00 in a zone at UTC+5:45 is column 9 with 15 minutes left over, never column 10.
+// byZone is true if the highlight named a timezone rather than an offset.
+func parseHighlightSpec(spec, date string) (start, minute, span, offset int, byZone bool, err error) {
+	spec = strings.TrimSpace(spec)
+	if bareHourPattern.MatchString(spec) {
+		spec += "L"
+	}
+	m := highlightPattern.FindStringSubmatch(spec)
+	if m == nil {
+		return 0, 0, 0, 0, false, fmt.Errorf("expected a time or range of times followed by a UTC offset, a timezone, or L for local time, i.e. 15+11, 3pm-5, 15-17+11, 15@Australia/Sydney, or 15-17L")
+	}
+	startMinutes, err := parseHighlightTime(m[1])
+	if err != nil {
+		return 0, 0, 0, 0, false, err
+	}
+	if startMinutes == 24*60 {
+		return 0, 0, 0, 0, false, fmt.Errorf("hour must be 0-23")
+	}
+	spanMinutes := 1
+	if m[2] != "" {
+		endMinutes, err := parseHighlightTime(m[2])
+		if err != nil {
+			return 0, 0, 0, 0, false, fmt.Errorf("end of range: %w", err)
+		}
+		// a range ending at or before its start wraps past midnight, so 15-15 is the whole day
+		spanMinutes = endMinutes - startMinutes
+		if spanMinutes <= 0 {
+			spanMinutes += 24 * 60
+		}
+		if spanMinutes > 24*60 {
+			return 0, 0, 0, 0, false, fmt.Errorf("range can't be longer than 24 hours")
+		}
+	}
+	byZone = m[4] != "" || m[5] != ""
+	switch {
+	case m[4] != "":
+		offset, err = parseOffset("@"+m[4], date)
+	case m[5] != "":
+		offset, err = parseOffset("@Local", date)
+	default:
+		offset, err = parseOffset(m[3], date)
+	}
+	if err != nil {
+		return 0, 0, 0, 0, false, err
+	}
+	utcMinutes := ((startMinutes-offset)%(24*60) + 24*60) % (24 * 60)
+	// count every column the range touches, i.e. 9:30-10:30 covers two
+	span = (utcMinutes%60+spanMinutes-1)/60 + 1
+	return utcMinutes / 60, utcMinutes % 60, span, offset, byZone, nil
+}
+
+// parseHighlightFlag parses each --highlight and returns the UTC hours of the table columns to highlight, without
+// duplicates, and the UTC times of highlights that don't start on the hour, i.e. 09:15 UTC. Each offset must match a
+// timezone in the table, so a typo doesn't silently highlight the wrong column. Highlights that name a timezone aren't
+// checked, since the timezone's own offset is used. If duration is more than zero, each highlight of a single time is
+// extended to cover that many minutes, and the columns after its first are returned as trailing, unless another
+// highlight starts in them.
+func parseHighlightFlag(specs []string, zones timezoneDetails, date string, duration int) (columns, trailing []int, instants []string, err error) {
+	for _, spec := range specs {
+		start, minute, span, offset, byZone, err := parseHighlightSpec(spec, date)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid highlight %q: %w", spec, err)
+		}
+		found := slices.ContainsFunc(zones, func(z timezoneDetail) bool { return z.offsetMinutes == offset })
+		if !byZone && !found {
+			return nil, nil, nil, fmt.Errorf("invalid highlight %q: no timezone in the table has UTC offset %s%s", spec, formatSecondsOffset(offset*60), highlightOffsetHint(spec, offset, zones))
+		}
+		if instant := fmt.Sprintf("%02d:%02d UTC", start, minute); minute != 0 && !slices.Contains(instants, instant) {
+			instants = append(instants, instant)
+		}
+		if duration > 0 && !isHighlightRange(spec) {
+			// the minutes past the hour the highlight starts at count towards the columns it touches
+			extended := highlightRange(start, minute+duration)
+			if !slices.Contains(columns, extended[0]) {
+				columns = append(columns, extended[0])
+			}
+			for _, column := range extended[1:] {
+				if !slices.Contains(trailing, column) {
+					trailing = append(trailing, column)
+				}
+			}
+			continue
+		}
+		// columns past the end of the table wrap around to its start
+		for i := 0; i < span; i++ {
+			if column := (start + i) % 24; !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	trailing = slices.DeleteFunc(trailing, func(c int) bool { return slices.Contains(columns, c) })
+	return columns, trailing, instants, nil
+}
+
+// isHighlightRange returns whether a highlight is a range of times, like 15-17+11, rather than a single time.
+func isHighlightRange(spec string) bool {
+	m := highlightPattern.FindStringSubmatch(strings.TrimSpace(spec))
+	return m != nil && m[2] != ""
+}
+
+// highlightRange returns the UTC hours of the table columns covered by a highlight starting at the UTC hour and lasting
+// the given number of minutes, wrapping past midnight to the start of the table. At least the starting column is
+// returned.
+func highlightRange(startUTCHour int, durationMinutes int) []int {
+	span := max((durationMinutes+59)/60, 1)
+	columns := make([]int, span)
+	for i := range columns {
+		columns[i] = (startUTCHour + i) % 24
+	}
+	return columns
+}
+
+// highlightOffsetHint returns a hint for a highlight whose offset matches no timezone in the table: the offsets in the
+// table, and the highlight with the nearest of them if it's within an hour, i.e. "; did you mean 15+11
+// (Australia/Sydney)?".
+func highlightOffsetHint(spec string, offset int, zones timezoneDetails) string {
+	if len(zones) == 0 {
+		return ""
+	}
+	sorted := slices.Clone(zones)
+	slices.SortStableFunc(sorted, func(a, b timezoneDetail) int { return a.offsetMinutes - b.offsetMinutes })
+	var offsets []string
+	for _, z := range sorted {
+		if o := formatOffset(z); !slices.Contains(offsets, o) {
+			offsets = append(offsets, o)
+		}
+	}
+	hint := ", the table has " + strings.Join(offsets, ", ")
+
+	// only offsets within an hour are suggested, and ties go to the zone listed first
+	nearest, nearestDiff := -1, 61
+	for i, z := range zones {
+		diff := z.offsetMinutes - offset
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < nearestDiff {
+			nearest, nearestDiff = i, diff
+		}
+	}
+	if nearest >= 0 {
+		z := zones[nearest]
+		suggestion := offsetSuffixPattern.ReplaceAllString(strings.TrimSpace(spec), formatHighlightOffset(z.offsetMinutes))
+		hint += fmt.Sprintf("; did you mean %s (%s)?", suggestion, z.name)
+	}
+	return hint
+}
+
+// formatHighlightOffset formats an offset in minutes the way --highlight accepts it, i.e. +11, -5, or +5:45.
+func formatHighlightOffset(minutes int) string {
+	sign := "+"
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	if minutes%60 != 0 {
+		return fmt.Sprintf("%s%d:%02d", sign, minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%s%d", sign, minutes/60)
+}
+
+// highlightColors returns the colors of columns highlighted with --highlight. They differ from the index column's so
+// both can be told apart.
+func highlightColors(colorEnabled bool) text.Colors {
+	if colorEnabled {
+		return text.Colors{text.BgHiYellow, text.FgBlack}
+	}
+	return text.Colors{text.FgHiYellow, text.Bold}
+}
+
+// highlightTrailingColors returns the colors of the columns after the first of a highlight extended with --duration.
+// They are a softer version of highlightColors, so the start of the highlight stands out.
+func highlightTrailingColors(colorEnabled bool) text.Colors {
+	if colorEnabled {
+		return text.Colors{text.BgYellow, text.FgBlack}
+	}
+	return text.Colors{text.FgYellow}
+}
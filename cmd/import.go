@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var importReplace bool
+
+// readSettings reads settings exported with timeBuddy export from a file, or stdin if path is -. JSON is accepted as
+// well as YAML, since YAML is a superset of it.
+func readSettings(path string) (map[string]interface{}, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return settings, nil
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Load settings printed by export into the config file",
+	Long: `Load settings printed by timeBuddy export, from a file or stdin if the file is -, into the config file. The settings
+are merged with the existing ones, replacing keys that are set in both, unless --replace is given, in which case the
+config file is replaced entirely. Every time zone is checked first, and nothing is written if any of them is invalid.
+
+Examples:
+
+  # Merge settings from a file:
+  $ timeBuddy import timeBuddy.yaml
+
+  # Replace the config file with the settings from another machine:
+  $ ssh other-machine timeBuddy export | timeBuddy import --replace -`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		settings, err := readSettings(args[0])
+		if err != nil {
+			l.Fatal().Str("file", args[0]).Err(err).Send()
+		}
+
+		// check the imported settings on their own viper, so viper lowercases and nests the keys the same way it will
+		// in the config file
+		iv := viper.New()
+		if err := iv.MergeConfigMap(settings); err != nil {
+			l.Fatal().Str("file", args[0]).Err(err).Send()
+		}
+		if invalid := invalidTimezoneRefs(configuredTimezoneRefs(iv)); len(invalid) > 0 {
+			l.Fatal().Str("file", args[0]).Err(fmt.Errorf("invalid timezones: %s", strings.Join(invalid, ", "))).Send()
+		}
+
+		fv := viper.New()
+		if !importReplace {
+			fv, err = readConfigFile()
+			if err != nil {
+				l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+			}
+		}
+		if err := fv.MergeConfigMap(iv.AllSettings()); err != nil {
+			l.Fatal().Str("file", args[0]).Err(err).Send()
+		}
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().BoolVar(&importReplace, "replace", false, "replace the config file instead of merging the settings into it")
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var infoOutput string
+
+// zoneInfo is a summary of a single timezone.
+type zoneInfo struct {
+	Timezone        string         `json:"timezone"`
+	Time            string         `json:"time"`
+	Abbreviation    string         `json:"abbreviation"`
+	Offset          string         `json:"offset"`
+	IsDST           bool           `json:"is_dst"`
+	StandardOffset  string         `json:"standard_offset"`
+	DaylightOffset  string         `json:"daylight_offset,omitempty"`
+	NextTransition  *dstTransition `json:"next_transition,omitempty"`
+	Notes           []string       `json:"notes,omitempty"`
+	standardSeconds int
+	daylightSeconds int
+	observesDST     bool
+}
+
+// formatSecondsOffset formats an offset in seconds east of UTC as ±HH:MM.
+func formatSecondsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// zoneOffsets returns the standard and daylight offsets, in seconds east of UTC, that the location uses in the year
+// starting at from. observesDST is false if the location doesn't use daylight saving time during that year.
+func zoneOffsets(loc *time.Location, from time.Time) (standard, daylight int, observesDST bool) {
+	limit := from.AddDate(1, 0, 0)
+	t := from.In(loc)
+	foundStandard := false
+	for !t.After(limit) {
+		_, offset := t.Zone()
+		if t.IsDST() {
+			daylight, observesDST = offset, true
+		} else if !foundStandard {
+			standard, foundStandard = offset, true
+		}
+		_, end := t.ZoneBounds()
+		if end.IsZero() {
+			break
+		}
+		t = end.In(loc)
+	}
+	if !foundStandard {
+		// the location was in daylight saving time for the whole year, which shouldn't happen, so fall back to now
+		_, standard = from.In(loc).Zone()
+	}
+	return standard, daylight, observesDST
+}
+
+// getInfo returns the summary of a timezone, noting unusual properties like half hour daylight saving time shifts or
+// offsets that aren't a whole or half hour.
+func getInfo(tz string) zoneInfo {
+	z, err := getZoneInfo(context.Background(), tz, date, l)
+	if err != nil {
+		l.Fatal().Err(err).Send()
+	}
+	loc, err := loadLocation(resolveAlias(tz))
+	if err != nil {
+		l.Fatal().Str("timezone", tz).Err(err).Send()
+	}
+
+	info := zoneInfo{
+		Timezone:     z.name,
+		Time:         z.currentTime.Format("Monday, Jan 2 2006 3:04PM"),
+		Abbreviation: z.abbreviation,
+		Offset:       formatSecondsOffset(z.offsetMinutes * 60),
+		IsDST:        z.currentTime.IsDST(),
+	}
+	info.standardSeconds, info.daylightSeconds, info.observesDST = zoneOffsets(loc, z.currentTime)
+	info.StandardOffset = formatSecondsOffset(info.standardSeconds)
+	if info.observesDST {
+		info.DaylightOffset = formatSecondsOffset(info.daylightSeconds)
+	}
+	if tr, ok := findDSTTransition(loc, z.currentTime, 366*24*time.Hour, true); ok {
+		info.NextTransition = &tr
+	}
+
+	if info.observesDST && info.daylightSeconds-info.standardSeconds != 3600 {
+		info.Notes = append(info.Notes, fmt.Sprintf("daylight saving time shifts the clock by %d minutes", (info.daylightSeconds-info.standardSeconds)/60))
+	}
+	switch (z.offsetMinutes%60 + 60) % 60 {
+	case 30:
+		info.Notes = append(info.Notes, "the offset is a half hour, not a whole hour")
+	case 15, 45:
+		info.Notes = append(info.Notes, "the offset is a quarter hour, not a whole or half hour")
+	}
+	return info
+}
+
+// printInfo prints the summary of a timezone as a list of labelled values.
+func printInfo(info zoneInfo) {
+	fmt.Println(info.Timezone)
+	fmt.Printf("  %-16s %s %s\n", "Time:", info.Time, info.Abbreviation)
+	fmt.Printf("  %-16s UTC%s\n", "Offset:", info.Offset)
+	fmt.Printf("  %-16s %t\n", "DST:", info.IsDST)
+	fmt.Printf("  %-16s UTC%s\n", "Standard offset:", info.StandardOffset)
+	if info.DaylightOffset != "" {
+		fmt.Printf("  %-16s UTC%s\n", "Daylight offset:", info.DaylightOffset)
+	} else {
+		fmt.Printf("  %-16s %s\n", "Daylight offset:", "none")
+	}
+	if info.NextTransition != nil {
+		fmt.Printf("  %-16s %s (%s)\n", "Next transition:", info.NextTransition.Local, formatDSTTransition(*info.NextTransition))
+	} else {
+		fmt.Printf("  %-16s %s\n", "Next transition:", "none")
+	}
+	for _, note := range info.Notes {
+		fmt.Printf("  %-16s %s\n", "Note:", note)
+	}
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info <timezone>...",
+	Short: "Show details about time zones",
+	Long: `Show the current time, abbreviation, and UTC offset of each time zone, along with whether it is in daylight saving
+time, its standard and daylight offsets, and its next transition. Unusual properties, like Australia/Lord_Howe's 30
+minute daylight saving time shift or Asia/Kathmandu's :45 offset, are noted.
+
+Examples:
+
+  # Show details about a time zone:
+  $ timeBuddy info Australia/Lord_Howe
+
+  # Show details about several time zones as JSON:
+  $ timeBuddy info America/New_York Asia/Kathmandu --output json`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTimezone,
+	Run: func(cmd *cobra.Command, args []string) {
+		infos := make([]zoneInfo, 0, len(args))
+		for _, tz := range args {
+			infos = append(infos, getInfo(tz))
+		}
+
+		switch infoOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(infos); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			for i, info := range infos {
+				if i > 0 {
+					fmt.Println()
+				}
+				printInfo(info)
+			}
+		default:
+			l.Fatal().Str("output", infoOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().StringVarP(&infoOutput, "output", "o", "text", "``output format, text or json")
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func Test_resolveTimezoneLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "US/Eastern", want: "America/New_York", wantOK: true},
+		{name: "EST5EDT", want: "America/New_York", wantOK: true},
+		{name: "Canada/Eastern", want: "America/Toronto", wantOK: true},
+		{name: "America/New_York"},
+		{name: "UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveTimezoneLink(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("resolveTimezoneLink(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_timezoneLinks(t *testing.T) {
+	// every link points to a timezone that loads and isn't a link itself
+	for link, canonical := range timezoneLinks {
+		if _, err := loadLocation(canonical); err != nil {
+			t.Errorf("%s links to %s, which doesn't load: %v", link, canonical, err)
+		}
+		if _, ok := timezoneLinks[canonical]; ok {
+			t.Errorf("%s links to %s, which is a link too", link, canonical)
+		}
+	}
+}
+
+func Test_getZoneInfo_links(t *testing.T) {
+	oldFollowLinks := followLinks
+	t.Cleanup(func() { followLinks = oldFollowLinks })
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "US/Eastern", want: "America/New_York"},
+		{name: "us/eastern", want: "America/New_York"},
+		{name: "EST5EDT", want: "America/New_York"},
+		{name: "Canada/Eastern", want: "America/Toronto"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, err := getZoneInfo(context.Background(), tt.want, "2024-06-15", l)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			followLinks = true
+			z, err := getZoneInfo(context.Background(), tt.name, "2024-06-15", l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if z.name != tt.want {
+				t.Errorf("getZoneInfo(%q) name = %q, want %q", tt.name, z.name, tt.want)
+			}
+			if z.offsetMinutes != canonical.offsetMinutes || z.abbreviation != canonical.abbreviation {
+				t.Errorf("getZoneInfo(%q) = %s %d, want %s %d as for %s", tt.name, z.abbreviation, z.offsetMinutes, canonical.abbreviation, canonical.offsetMinutes, tt.want)
+			}
+
+			// with --follow-links=false the name is kept as given, in its canonical case
+			followLinks = false
+			z, err = getZoneInfo(context.Background(), tt.name, "2024-06-15", l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.EqualFold(z.name, tt.name) {
+				t.Errorf("getZoneInfo(%q) name = %q with --follow-links=false, want the link name", tt.name, z.name)
+			}
+		})
+	}
+}
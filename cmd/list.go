@@ -4,16 +4,23 @@ Copyright © 2024 Jake Rogers <code@supportoss.org>
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
 	area         string
+	listFormat   string
 	timezonesAll = []string{
 		"Africa/Abidjan",
 		"Africa/Accra",
@@ -615,6 +622,99 @@ var (
 	}
 )
 
+var (
+	// timezoneDescriptions holds the completion description of each timezone, built by timezoneDescriptionsOnce
+	timezoneDescriptions     map[string]string
+	timezoneDescriptionsOnce sync.Once
+)
+
+var (
+	// timezoneNames maps each timezone in timezonesAll, keyed by timezoneNameKey, to its canonical spelling. It is built
+	// the first time a timezone is matched.
+	timezoneNames     map[string]string
+	timezoneNamesOnce sync.Once
+)
+
+// timezoneNameKey returns the key a timezone is matched by, lowercase with spaces and hyphens as underscores, so
+// america/new_york, America/New York, and America/New-York all match America/New_York.
+func timezoneNameKey(tz string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(tz)))
+}
+
+// matchTimezoneName returns the canonical spelling of a timezone typed with a different case or separators, and
+// whether it matched one.
+func matchTimezoneName(tz string) (string, bool) {
+	timezoneNamesOnce.Do(func() {
+		timezoneNames = make(map[string]string, len(timezonesAll))
+		for _, name := range timezonesAll {
+			timezoneNames[timezoneNameKey(name)] = name
+		}
+	})
+	canonical, ok := timezoneNames[timezoneNameKey(tz)]
+	return canonical, ok
+}
+
+// describeTimezone returns the completion description of a timezone: its city, with the region for names like
+// America/Argentina/Buenos_Aires, and its current UTC offset, i.e. "New York (UTC-05:00)".
+func describeTimezone(tz string) string {
+	parts := strings.Split(strings.ReplaceAll(tz, "_", " "), "/")
+	place := parts[len(parts)-1]
+	if len(parts) > 2 {
+		place += ", " + parts[len(parts)-2]
+	}
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return place
+	}
+	_, offset := time.Now().In(loc).Zone()
+	return fmt.Sprintf("%s (UTC%s)", place, formatSecondsOffset(offset))
+}
+
+// timezoneCompletion returns a timezone as a completion candidate with its description. The descriptions of every
+// timezone are built on first use, since most completions list all of them.
+func timezoneCompletion(tz string) string {
+	timezoneDescriptionsOnce.Do(func() {
+		timezoneDescriptions = make(map[string]string, len(timezonesAll))
+		for _, name := range timezonesAll {
+			timezoneDescriptions[name] = describeTimezone(name)
+		}
+	})
+	desc, ok := timezoneDescriptions[tz]
+	if !ok {
+		desc = describeTimezone(tz)
+	}
+	return tz + "\t" + desc
+}
+
+// completeTimezone completes timezone names, described by their city and current UTC offset. The recently used
+// timezones saved in the config file are listed first, followed by the aliases, and then every other timezone.
+func completeTimezone(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var recent []string
+	var aliases map[string]string
+	if fv, err := readConfigFile(); err == nil {
+		recent = fv.GetStringSlice("recently_used")
+		aliases = aliasTimezones(fv.GetStringMap("aliases"))
+	}
+	completions := make([]string, 0, len(recent)+len(aliases)+len(timezonesAll))
+	for _, tz := range recent {
+		completions = append(completions, timezoneCompletion(tz)+", recent")
+	}
+	aliasNames := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		aliasNames = append(aliasNames, alias)
+	}
+	sort.Strings(aliasNames)
+	for _, alias := range aliasNames {
+		completions = append(completions, alias+"\t(alias for "+aliases[alias]+")")
+	}
+	for _, tz := range timezonesAll {
+		if !slices.Contains(recent, tz) {
+			completions = append(completions, timezoneCompletion(tz))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveDefault
+}
+
 // listAreas returns a map of time zone areas and their corresponding locations.
 // It iterates over the timezonesAll slice and extracts the area and location from each time zone string.
 // The extracted area and location are then added to the tzAreas map. The map is then returned.
@@ -629,6 +729,38 @@ func listAreas() map[string][]string {
 	return tzAreas
 }
 
+// renderListJSON writes the areas and their locations as JSON, i.e. {"areas": {"America": ["New_York", ...], ...}}.
+func renderListJSON(areas map[string][]string, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Areas map[string][]string `json:"areas"`
+	}{Areas: areas})
+}
+
+// renderListCSV writes the areas and their locations as CSV with an area,location header, sorted by area.
+func renderListCSV(areas map[string][]string, w io.Writer) error {
+	names := make([]string, 0, len(areas))
+	for k := range areas {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"area", "location"}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		for _, location := range areas[name] {
+			if err := cw.Write([]string{name, location}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List time zones",
@@ -648,7 +780,11 @@ Examples:
   $ timeBuddy list --areas
 
   # List all timezones in a specific area:
-  $ timeBuddy list --locations America`,
+  $ timeBuddy list --locations America
+
+  # List all areas and their locations as JSON or CSV:
+  $ timeBuddy list --format json
+  $ timeBuddy list --format csv --locations Europe`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("locations") {
 			tzAreas := listAreas()
@@ -660,6 +796,27 @@ Examples:
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		tzAreas := listAreas()
+
+		// machine-readable formats list every area and its locations, or only the area requested with --locations
+		if listFormat != "text" {
+			if cmd.Flags().Changed("locations") {
+				tzAreas = map[string][]string{area: tzAreas[area]}
+			}
+			var err error
+			switch listFormat {
+			case "json":
+				err = renderListJSON(tzAreas, os.Stdout)
+			case "csv":
+				err = renderListCSV(tzAreas, os.Stdout)
+			default:
+				err = fmt.Errorf("invalid format, expected text, json, or csv")
+			}
+			if err != nil {
+				l.Fatal().Str("format", listFormat).Err(err).Send()
+			}
+			return
+		}
+
 		if cmd.Flags().Changed("areas") {
 			// Extract and sort the keys
 			areas := make([]string, 0, len(tzAreas))
@@ -692,6 +849,7 @@ Examples:
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolP("areas", "a", false, "list available timezone areas. i.e. America, Europe, etc.")
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "text", "``output format, text, json, or csv. json and csv list every area and its locations, or only the area given with --locations.")
 	listCmd.Flags().StringVarP(&area, "locations", "l", "", "``list timezones for the area requested, i.e. 'America' would show New_York, Denver, etc.")
 	listCmd.Flags().BoolP("timezones", "t", false, "list all timezone")
 	listCmd.MarkFlagsMutuallyExclusive("areas", "locations", "timezones")
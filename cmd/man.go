@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JakeTRogers/timeBuddy/internal/buildinfo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
+)
+
+var (
+	manOutput string
+	manPrefix string
+)
+
+// manSection is the man page section the pages are generated for, user commands
+const manSection = "1"
+
+// manName returns the name of a command's man page, without the section, i.e. timeBuddy-config-set.
+func manName(c *cobra.Command) string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "-")
+}
+
+// manPaths returns the paths of the man pages of the command and each of its available subcommands in dir, in the
+// order doc.GenManTree names them.
+func manPaths(c *cobra.Command, dir string) []string {
+	paths := []string{filepath.Join(dir, manName(c)+"."+manSection)}
+	for _, sub := range c.Commands() {
+		if sub.IsAvailableCommand() && !sub.IsAdditionalHelpTopicCommand() {
+			paths = append(paths, manPaths(sub, dir)...)
+		}
+	}
+	return paths
+}
+
+// unquoteUsages removes the backticks naming a flag's value from the usage of each flag of the command and its
+// subcommands, since doc.GenManTree prints the usage as is, unlike --help.
+func unquoteUsages(c *cobra.Command) {
+	unquote := func(f *pflag.Flag) {
+		_, f.Usage = pflag.UnquoteUsage(f)
+	}
+	c.Flags().VisitAll(unquote)
+	c.PersistentFlags().VisitAll(unquote)
+	for _, sub := range c.Commands() {
+		unquoteUsages(sub)
+	}
+}
+
+// genManTree writes a man page for the command and each of its available subcommands to dir, and returns the paths
+// of the files written.
+func genManTree(c *cobra.Command, dir string, date time.Time) ([]string, error) {
+	header := &doc.GenManHeader{
+		Section: manSection,
+		Date:    &date,
+		Source:  "timeBuddy " + buildinfo.Version,
+		Manual:  "timeBuddy Manual",
+	}
+	unquoteUsages(c)
+	if err := doc.GenManTree(c, header, dir); err != nil {
+		return nil, err
+	}
+	return manPaths(c, dir), nil
+}
+
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages for timeBuddy and its subcommands",
+	Long: `Generate a man page for timeBuddy and each of its subcommands, named after the command, i.e. timeBuddy-config-set.1.
+The pages are written to ./man, or the directory given with --output. Use --prefix to write them to the man1 directory
+of an installation prefix, i.e. --prefix /usr writes them to /usr/share/man/man1. The path of each page is printed.
+
+Examples:
+
+  # Generate the man pages in ./man:
+  $ timeBuddy man
+
+  # Install the man pages:
+  $ sudo timeBuddy man --prefix /usr/local`,
+	Args: cobra.NoArgs,
+	// Override the root command's PersistentPreRunE so generating docs doesn't create the config file
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := manOutput
+		if manPrefix != "" {
+			dir = filepath.Join(manPrefix, "share", "man", "man"+manSection)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			l.Fatal().Str("output", dir).Err(err).Send()
+		}
+		paths, err := genManTree(cmd.Root(), dir, time.Now())
+		if err != nil {
+			l.Fatal().Str("output", dir).Err(err).Send()
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(manCmd)
+	manCmd.Flags().StringVarP(&manOutput, "output", "o", "man", "``directory to write the man pages to")
+	manCmd.Flags().StringVar(&manPrefix, "prefix", "", "``installation prefix, the man pages are written to <prefix>/share/man/man1")
+	manCmd.MarkFlagsMutuallyExclusive("output", "prefix")
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_genManTree(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := genManTree(rootCmd, dir, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("man page %s wasn't written: %v", path, err)
+		}
+	}
+
+	root := filepath.Join(dir, "timeBuddy.1")
+	if len(paths) == 0 || paths[0] != root {
+		t.Fatalf("paths = %v, want %s first", paths, root)
+	}
+	page, err := os.ReadFile(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`.TH "TIMEBUDDY" "1" "Jun 2024"`,
+		`\fB--timezone\fP`,
+		"timezone to use for time conversion. Accepts timezone name, like America/New_York, or city, like Paris.",
+	}
+	for _, s := range want {
+		if !strings.Contains(string(page), s) {
+			t.Errorf("root man page doesn't contain %q", s)
+		}
+	}
+	if strings.Contains(string(page), "``") {
+		t.Errorf("root man page contains the backticks of a flag's usage")
+	}
+}
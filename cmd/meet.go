@@ -0,0 +1,254 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	meetDate         string
+	meetOutput       string
+	meetTimezones    []string
+	meetWorkingHours []string
+)
+
+// meetZone is a timezone taking part in a meeting along with its working hours, in minutes since local midnight.
+type meetZone struct {
+	name  string
+	loc   *time.Location
+	hours [2]int
+}
+
+// meetRange is a contiguous range of UTC hours during which the same set of zones are inside working hours.
+type meetRange struct {
+	StartUTC string   `json:"start_utc"`
+	EndUTC   string   `json:"end_utc"`
+	Zones    []string `json:"zones"`
+	start    time.Time
+	end      time.Time
+}
+
+// meetResult is the outcome of searching for overlapping working hours.
+type meetResult struct {
+	Date        string      `json:"date"`
+	FullOverlap bool        `json:"full_overlap"`
+	ZoneCount   int         `json:"zone_count"`
+	Overlapping int         `json:"overlapping"`
+	Ranges      []meetRange `json:"ranges"`
+	Best        *meetRange  `json:"best,omitempty"`
+}
+
+// inWorkHours reports whether the whole hour starting at t falls inside the zone's working hours.
+func (z meetZone) inWorkHours(t time.Time) bool {
+	lt := t.In(z.loc)
+	return inWorkingHours(lt.Hour()*60+lt.Minute(), 60, z.hours)
+}
+
+// centerScore returns the squared number of minutes the middle of the hour starting at t is from the middle of the
+// zone's working hours. Lower values are more centered in the zone's day. The distance is squared so that, when the
+// scores of several zones are added together, an hour that is reasonable for everyone beats one that is ideal for some
+// zones and at the edge of the day for others.
+func (z meetZone) centerScore(t time.Time) int {
+	lt := t.In(z.loc)
+	distance := lt.Hour()*60 + lt.Minute() + 30 - (z.hours[0]+z.hours[1])/2
+	return distance * distance
+}
+
+// findMeetingHours checks each UTC hour of the given day and returns the ranges of hours where the most zones are
+// inside working hours, along with the best hour, which is the one most centered in everyone's working day. If every
+// zone overlaps, the result is marked as a full overlap.
+func findMeetingHours(day time.Time, zones []meetZone) meetResult {
+	result := meetResult{Date: day.Format(time.DateOnly), ZoneCount: len(zones)}
+
+	// determine which zones are inside working hours for each hour of the day
+	inHours := make([][]meetZone, 24)
+	for h := range inHours {
+		t := day.Add(time.Duration(h) * time.Hour)
+		for _, z := range zones {
+			if z.inWorkHours(t) {
+				inHours[h] = append(inHours[h], z)
+			}
+		}
+		result.Overlapping = max(result.Overlapping, len(inHours[h]))
+	}
+	result.FullOverlap = result.Overlapping == len(zones)
+	if result.Overlapping == 0 {
+		return result
+	}
+
+	// group contiguous hours with the same set of zones into ranges, and find the most centered hour
+	bestScore := -1
+	for h := 0; h < 24; h++ {
+		if len(inHours[h]) != result.Overlapping {
+			continue
+		}
+		t := day.Add(time.Duration(h) * time.Hour)
+		names := make([]string, len(inHours[h]))
+		score := 0
+		for i, z := range inHours[h] {
+			names[i] = z.name
+			score += z.centerScore(t)
+		}
+
+		if n := len(result.Ranges); n > 0 && result.Ranges[n-1].end.Equal(t) && strings.Join(result.Ranges[n-1].Zones, ",") == strings.Join(names, ",") {
+			result.Ranges[n-1].end = t.Add(time.Hour)
+		} else {
+			result.Ranges = append(result.Ranges, meetRange{Zones: names, start: t, end: t.Add(time.Hour)})
+		}
+
+		if bestScore < 0 || score < bestScore {
+			bestScore = score
+			result.Best = &meetRange{Zones: names, start: t, end: t.Add(time.Hour)}
+		}
+	}
+
+	for i := range result.Ranges {
+		result.Ranges[i].StartUTC = result.Ranges[i].start.Format("15:04")
+		result.Ranges[i].EndUTC = result.Ranges[i].end.Format("15:04")
+	}
+	result.Best.StartUTC = result.Best.start.Format("15:04")
+	result.Best.EndUTC = result.Best.end.Format("15:04")
+	return result
+}
+
+// printMeetTable prints the meeting hour ranges as a table with a column for each zone showing the local time range.
+func printMeetTable(result meetResult, zones []meetZone) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.Style().Title.Align = text.AlignCenter
+	t.Style().Format.Header = text.FormatDefault
+	if result.FullOverlap {
+		t.SetTitle("Working Hours Overlap: %s", result.Date)
+	} else {
+		t.SetTitle("Partial Overlap (%d of %d zones): %s", result.Overlapping, result.ZoneCount, result.Date)
+	}
+
+	header := table.Row{"UTC"}
+	for _, z := range zones {
+		header = append(header, z.name)
+	}
+	t.AppendHeader(header)
+
+	for _, r := range result.Ranges {
+		row := table.Row{fmt.Sprintf("%s-%s", r.StartUTC, r.EndUTC)}
+		for _, z := range zones {
+			cell := "-"
+			for _, name := range r.Zones {
+				if name == z.name {
+					cell = fmt.Sprintf("%s-%s", r.start.In(z.loc).Format("15:04"), r.end.In(z.loc).Format("15:04"))
+					break
+				}
+			}
+			row = append(row, cell)
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+
+	if result.Best != nil {
+		fmt.Printf("Best hour: %s-%s UTC\n", result.Best.StartUTC, result.Best.EndUTC)
+	}
+}
+
+var meetCmd = &cobra.Command{
+	Use:   "meet",
+	Short: "Find overlapping working hours",
+	Long: `Find the hours where all of the configured time zones are inside working hours, and the best hour for a meeting,
+which is the hour most centered in everyone's working day.
+
+Working hours default to 09:00-17:00, or the working_hours set for a time zone in the config file. Use --working-hours
+08:00-16:00 to change the default for all time zones, or --working-hours America/New_York=07:00-15:00 to change them for
+a single time zone. If there are no hours where every time zone overlaps, the hours where the most time zones overlap
+are shown instead.
+
+Examples:
+
+  # Find overlapping working hours for the time zones in the config file:
+  $ timeBuddy meet
+
+  # Find overlapping working hours for specific time zones, with an early start in New York:
+  $ timeBuddy meet -z America/New_York -z Europe/London --working-hours America/New_York=07:00-15:00
+
+  # Output the result as JSON:
+  $ timeBuddy meet --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		meetTimezones = deduplicateSlice(meetTimezones)
+		if len(meetTimezones) == 0 {
+			l.Fatal().Err(fmt.Errorf("no timezones configured, use --timezone to specify one")).Send()
+		}
+
+		defaultHours, workingHours, err := loadWorkingHoursFlag(meetWorkingHours)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+
+		zones := make([]meetZone, 0, len(meetTimezones))
+		for _, name := range meetTimezones {
+			tz := resolveAlias(name)
+			loc, err := loadLocation(tz)
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			wh, ok := workingHours[strings.ToLower(tz)]
+			if !ok {
+				wh = defaultHours
+			}
+			zones = append(zones, meetZone{name: tz, loc: loc, hours: wh})
+		}
+
+		day := time.Now().UTC()
+		if cmd.Flags().Changed("date") {
+			resolved, err := resolveRelativeDate(meetDate)
+			if err != nil {
+				l.Fatal().Str("date", meetDate).Err(err).Send()
+			}
+			day, err = time.Parse(time.DateOnly, resolved)
+			if err != nil {
+				l.Fatal().Str("date", meetDate).Err(err).Send()
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+		result := findMeetingHours(day, zones)
+
+		switch meetOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "table":
+			if result.Overlapping == 0 {
+				fmt.Println("No time zones are inside working hours on", result.Date)
+				return
+			}
+			printMeetTable(result, zones)
+		default:
+			l.Fatal().Str("output", meetOutput).Err(fmt.Errorf("invalid output format, expected table or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(meetCmd)
+	meetCmd.Flags().StringVarP(&meetDate, "date", "d", "", "``date to find overlapping working hours for. Accepts the same values as timeBuddy --date. Defaults to today.")
+	meetCmd.Flags().StringVarP(&meetOutput, "output", "o", "table", "``output format, table or json")
+	meetCmd.Flags().StringArrayVarP(&meetTimezones, "timezone", "z", []string{}, "``timezone to include. Can be used multiple times. Defaults to the timezones in the config file.")
+	meetCmd.Flags().StringArrayVarP(&meetWorkingHours, "working-hours", "w", []string{}, "``working hours as HH:MM-HH:MM, or timezone=HH:MM-HH:MM to override a single timezone. Can be used multiple times.")
+	err := meetCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+// migrationFunc upgrades the settings in a config file by one schema version.
+type migrationFunc func(*viper.Viper) error
+
+// migrations upgrade the config file from the schema version at their index to the next one. Config files written
+// before schema_version was added are version 0. Append a migration whenever the schema changes, and
+// currentSchemaVersion follows.
+var migrations = []migrationFunc{
+	migrateV0ToV1,
+}
+
+// currentSchemaVersion is the schema version written to the config file.
+var currentSchemaVersion = len(migrations)
+
+// schemaMapKeys are maps that are always in the config file since schema version 1, empty if nothing has been added to
+// them, so they show up for editing. Viper drops empty maps, so atomicWriteConfig adds them back.
+var schemaMapKeys = []string{"aliases", "groups"}
+
+// migrateV0ToV1 adds the groups and aliases maps.
+func migrateV0ToV1(fv *viper.Viper) error {
+	for _, key := range schemaMapKeys {
+		if !fv.IsSet(key) {
+			fv.Set(key, map[string]interface{}{})
+		}
+	}
+	return nil
+}
+
+// migrateConfig applies the migrations needed to bring the config file up to the current schema version, then writes it
+// back. It returns true if the file was changed. Config files from a newer version of timeBuddy are left alone. Each
+// migration is logged to log.
+func migrateConfig(log *zerolog.Logger) (bool, error) {
+	fv, err := readConfigFile()
+	if err != nil {
+		return false, err
+	}
+	version := fv.GetInt("schema_version")
+	if version > currentSchemaVersion {
+		log.Warn().Int("schema_version", version).Int("supported", currentSchemaVersion).Msg("Config file is from a newer version of timeBuddy:")
+		return false, nil
+	}
+	if version == currentSchemaVersion {
+		return false, nil
+	}
+	for ; version < currentSchemaVersion; version++ {
+		if err := migrations[version](fv); err != nil {
+			return false, err
+		}
+		log.Info().Int("from", version).Int("to", version+1).Msg("Migrated config file schema:")
+	}
+	// atomicWriteConfig sets schema_version
+	return true, atomicWriteConfig(fv, getConfigPath())
+}
+
+// legacyConfigPath returns where older versions kept the config file: %APPDATA%\.timeBuddy.yaml on Windows, otherwise
+// $HOME/.config/.timeBuddy.yaml.
+func legacyConfigPath() string {
+	dir := filepath.Join(os.Getenv("HOME"), ".config")
+	if runtime.GOOS == "windows" {
+		dir = os.Getenv("APPDATA")
+	}
+	return filepath.Join(dir, configName+"."+configType)
+}
+
+// moveLegacyConfig moves the config file from legacyConfigPath to getConfigPath, if there is one there and none at the
+// new path. It returns true if the file was moved. A file that can't be renamed, i.e. when $XDG_CONFIG_HOME is on
+// another filesystem, is copied, then removed.
+func moveLegacyConfig() (bool, error) {
+	legacy, path := legacyConfigPath(), getConfigPath()
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	info, err := os.Stat(legacy)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err := os.Rename(legacy, path); err == nil {
+		return true, nil
+	}
+	content, err := os.ReadFile(legacy)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, os.Remove(legacy)
+}
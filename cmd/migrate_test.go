@@ -0,0 +1,236 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_moveLegacyConfig(t *testing.T) {
+	// the config directory only follows $XDG_CONFIG_HOME on Linux and the BSDs
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("config directory doesn't follow XDG_CONFIG_HOME on", runtime.GOOS)
+	}
+	const legacyContent = "timezone:\n  - Asia/Tokyo\n"
+	const newContent = "timezone:\n  - Europe/London\n"
+
+	tests := []struct {
+		name      string
+		legacy    bool
+		existing  bool
+		wantMoved bool
+		// want is the content of the config file afterwards, or empty if there is none
+		want string
+	}{
+		{
+			name: "fresh install",
+		},
+		{
+			name:      "legacy config file is moved",
+			legacy:    true,
+			wantMoved: true,
+			want:      legacyContent,
+		},
+		{
+			name:     "existing config file is kept",
+			legacy:   true,
+			existing: true,
+			want:     newContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, "")
+			configFile = ""
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+			path := filepath.Join(home, "xdg", configDirName, configFileName+"."+configType)
+			if got := getConfigPath(); got != path {
+				t.Fatalf("getConfigPath() = %s, want %s", got, path)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			legacy := legacyConfigPath()
+			if tt.legacy {
+				if err := os.MkdirAll(filepath.Dir(legacy), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(legacy, []byte(legacyContent), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.existing {
+				if err := os.WriteFile(path, []byte(newContent), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			moved, err := moveLegacyConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("moveLegacyConfig() = %v, want %v", moved, tt.wantMoved)
+			}
+			content, err := os.ReadFile(path)
+			if tt.want == "" {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("config file exists, want none: %v", err)
+				}
+			} else if string(content) != tt.want {
+				t.Errorf("config file = %q, %v, want %q", content, err, tt.want)
+			}
+			// a moved file keeps its permissions, and the legacy file is only removed once moved
+			if moved {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != 0o600 {
+					t.Errorf("moved config file mode = %v, want 0600", info.Mode().Perm())
+				}
+			}
+			_, err = os.Stat(legacy)
+			if legacyExists := err == nil; legacyExists != (tt.legacy && !tt.wantMoved) {
+				t.Errorf("legacy config file exists = %v, want %v", legacyExists, tt.legacy && !tt.wantMoved)
+			}
+		})
+	}
+}
+
+func Test_initializeConfig_legacyConfig(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("config directory doesn't follow XDG_CONFIG_HOME on", runtime.GOOS)
+	}
+	useTestConfig(t, "")
+	configFile = ""
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	if err := os.MkdirAll(filepath.Dir(legacyConfigPath()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacyConfigPath(), []byte("timezone:\n  - Asia/Tokyo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the config directory doesn't exist yet, and the legacy file is moved into it, then migrated
+	cmd, tzs := newTestCommand()
+	if err := initializeConfig(cmd, viper.New(), l); err != nil {
+		t.Fatal(err)
+	}
+	if len(*tzs) != 1 || (*tzs)[0] != "Asia/Tokyo" {
+		t.Errorf("timezone = %v, want [Asia/Tokyo]", *tzs)
+	}
+	fv, err := readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fv.GetInt("schema_version"); got != currentSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", got, currentSchemaVersion)
+	}
+	for _, key := range schemaMapKeys {
+		if got, ok := readConfigYAML(t)[key].(map[string]interface{}); !ok || len(got) != 0 {
+			t.Errorf("%s = %v, want an empty map", key, got)
+		}
+	}
+	if _, err := os.Stat(legacyConfigPath()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("legacy config file wasn't removed: %v", err)
+	}
+}
+
+// readConfigYAML returns the config file as written, so empty maps, which viper doesn't report as set, can be checked.
+func readConfigYAML(t *testing.T) map[string]interface{} {
+	t.Helper()
+	content, err := os.ReadFile(getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func Test_migrateConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantChanged bool
+		// wantMaps are the aliases and groups maps expected in the config file afterwards, or nil if there are none
+		wantMaps          map[string]map[string]interface{}
+		wantSchemaVersion int
+	}{
+		{
+			name:              "v0 config file gets empty maps",
+			content:           "timezone:\n  - Asia/Tokyo\n",
+			wantChanged:       true,
+			wantMaps:          map[string]map[string]interface{}{"aliases": {}, "groups": {}},
+			wantSchemaVersion: 1,
+		},
+		{
+			name:        "v0 config file keeps its maps",
+			content:     "timezone:\n  - Asia/Tokyo\naliases:\n  hq: America/New_York\ngroups:\n  apac:\n    - Asia/Tokyo\n    - Australia/Sydney\n",
+			wantChanged: true,
+			wantMaps: map[string]map[string]interface{}{
+				"aliases": {"hq": "America/New_York"},
+				"groups":  {"apac": []interface{}{"Asia/Tokyo", "Australia/Sydney"}},
+			},
+			wantSchemaVersion: 1,
+		},
+		{
+			name:              "current config file is left alone",
+			content:           "schema_version: 1\ntimezone:\n  - Asia/Tokyo\n",
+			wantSchemaVersion: 1,
+		},
+		{
+			name:              "newer config file is left alone",
+			content:           "schema_version: 99\ntimezone:\n  - Asia/Tokyo\n",
+			wantSchemaVersion: 99,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, tt.content)
+			changed, err := migrateConfig(l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("migrateConfig() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			settings := readConfigYAML(t)
+			if got := settings["schema_version"]; got != tt.wantSchemaVersion {
+				t.Errorf("schema_version = %v, want %d", got, tt.wantSchemaVersion)
+			}
+			if got := settings["timezone"]; !reflect.DeepEqual(got, []interface{}{"Asia/Tokyo"}) {
+				t.Errorf("timezone = %v, want [Asia/Tokyo]", got)
+			}
+			for _, key := range schemaMapKeys {
+				got, ok := settings[key]
+				want, wantOK := tt.wantMaps[key]
+				if ok != wantOK {
+					t.Errorf("config file has %s = %v, want %v", key, ok, wantOK)
+					continue
+				}
+				if wantOK && !reflect.DeepEqual(got, map[string]interface{}(want)) {
+					t.Errorf("%s = %#v, want %#v", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nextDSTTimezones []string
+	nextDSTWithin    string
+)
+
+// errNoDSTTransition is returned by findNextDSTTransition when the offset doesn't change within the lookahead window
+var errNoDSTTransition = errors.New("no DST transition within the lookahead window")
+
+// utcOffsetMinutes returns the UTC offset of the location at time t, in minutes.
+func utcOffsetMinutes(loc *time.Location, t time.Time) int {
+	_, offset := t.In(loc).Zone()
+	return offset / 60
+}
+
+// findNextDSTTransition returns the first change in the location's UTC offset after from, the new offset in minutes,
+// and whether it is a spring forward, where the offset increases, rather than a fall back. errNoDSTTransition is
+// returned if the offset doesn't change within maxLookahead. See dstBoundary.
+func findNextDSTTransition(loc *time.Location, from time.Time, maxLookahead time.Duration) (time.Time, int, bool, error) {
+	if loc == nil {
+		return time.Time{}, 0, false, fmt.Errorf("location is required")
+	}
+	if maxLookahead <= 0 {
+		return time.Time{}, 0, false, fmt.Errorf("invalid lookahead %s, expected a positive duration", maxLookahead)
+	}
+
+	transition, ok := dstBoundary(loc, from, maxLookahead, true)
+	if !ok {
+		return time.Time{}, 0, false, errNoDSTTransition
+	}
+	newOffset := utcOffsetMinutes(loc, transition)
+	return transition.UTC(), newOffset, newOffset > utcOffsetMinutes(loc, from), nil
+}
+
+var nextDSTCmd = &cobra.Command{
+	Use:   "next-dst",
+	Short: "Show the next daylight saving time transition for each time zone",
+	Long: `Show the next change in UTC offset, i.e. the start or end of daylight saving time, for each of the configured time
+zones. Each transition is shown in UTC and the time zone's local time, along with whether clocks spring forward or fall
+back and the UTC offset after it. Time zones without a transition within --within show none.
+
+Examples:
+
+  # Show the next transition for the time zones in the config file:
+  $ timeBuddy next-dst
+
+  # Show transitions for New York within the next 90 days:
+  $ timeBuddy next-dst --timezone America/New_York --within 90d`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		window, err := parseDayDuration(nextDSTWithin)
+		if err != nil {
+			l.Fatal().Str("within", nextDSTWithin).Err(err).Send()
+		}
+		targets := deduplicateSlice(nextDSTTimezones)
+		if len(targets) == 0 {
+			targets = []string{"Local"}
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleRounded)
+		t.Style().Format.Header = text.FormatDefault
+		t.AppendHeader(table.Row{"Timezone", "Next Transition (UTC)", "Local Time", "Type", "New UTC Offset"})
+
+		now := timeNow()
+		for _, name := range targets {
+			tz := resolveAlias(name)
+			loc, err := loadLocation(tz)
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			transition, offset, spring, err := findNextDSTTransition(loc, now, window)
+			if errors.Is(err, errNoDSTTransition) {
+				t.AppendRow(table.Row{tz, "none", "", "", ""})
+				continue
+			}
+			if err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+			kind := "fall"
+			if spring {
+				kind = "spring"
+			}
+			t.AppendRow(table.Row{
+				tz,
+				transition.Format("2006-01-02 15:04 MST"),
+				transition.In(loc).Format("Mon, Jan 2 2006 3:04PM MST"),
+				kind,
+				"UTC" + formatOffsetDiff(offset*60),
+			})
+		}
+		t.Render()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nextDSTCmd)
+	nextDSTCmd.Flags().StringArrayVarP(&nextDSTTimezones, "timezone", "z", []string{}, "``timezone to show the next transition for. Can be used multiple times. Defaults to the timezones in the config file.")
+	nextDSTCmd.Flags().StringVarP(&nextDSTWithin, "within", "w", "365d", "``how far ahead to search for a transition, in days like 90d or a duration like 72h")
+	if err := nextDSTCmd.RegisterFlagCompletionFunc("timezone", completeTimezone); err != nil {
+		l.Error().Err(err).Send()
+	}
+}
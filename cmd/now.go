@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	nowSeparator  string
+	nowTimezones  []string
+	nowTwelveHour bool
+)
+
+// nowLabel returns the label to show for a timezone in the now command. A custom label from the labels map in the
+// config file is used if one exists, otherwise Local is shown as-is and other timezones use their abbreviation.
+// Viper lowercases map keys, so the timezone is looked up case-insensitively.
+func nowLabel(z timezoneDetail, labels map[string]string) string {
+	if label, ok := labels[strings.ToLower(z.name)]; ok && label != "" {
+		return label
+	}
+	if z.name == "Local" {
+		return z.name
+	}
+	return z.abbreviation
+}
+
+// formatNow returns the current time in each zone on a single line, joined by the separator.
+func formatNow(zones timezoneDetails, labels map[string]string, sep string, twelveHour bool) string {
+	layout := "15:04"
+	if twelveHour {
+		layout = "3:04PM"
+	}
+	parts := make([]string, len(zones))
+	for i, z := range zones {
+		parts[i] = fmt.Sprintf("%s %s", nowLabel(z, labels), z.currentTime.Format(layout))
+	}
+	return strings.Join(parts, sep)
+}
+
+var nowCmd = &cobra.Command{
+	Use:   "now",
+	Short: "Print the current time in each time zone on one line",
+	Long: `Print the current time in each of the configured time zones on a single line, without a table, title, or color. This
+is useful for embedding in status bars and prompts, i.e. tmux or starship.
+
+Time zones are labeled with their abbreviation. Custom labels can be set in the config file with a labels map:
+
+  labels:
+    America/New_York: NYC
+    Europe/London: LON
+
+Examples:
+
+  # Print the current time in the configured time zones:
+  $ timeBuddy now
+  Local 09:12 · EDT 09:12 · BST 14:12
+
+  # Use a different separator and 12-hour time:
+  $ timeBuddy now --sep " | " --twelve-hour`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nowTimezones = deduplicateSlice(nowTimezones)
+		if len(nowTimezones) == 0 {
+			nowTimezones = []string{"Local"}
+		}
+
+		today := time.Now().Format(time.DateOnly)
+		var zones timezoneDetails
+		for _, tz := range nowTimezones {
+			z, err := getZoneInfo(cmd.Context(), tz, today, l)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			zones = append(zones, z)
+		}
+
+		fmt.Println(formatNow(zones, v.GetStringMapString("labels"), nowSeparator, nowTwelveHour))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nowCmd)
+	nowCmd.Flags().StringVarP(&nowSeparator, "sep", "s", " · ", "``separator placed between time zones")
+	nowCmd.Flags().StringArrayVarP(&nowTimezones, "timezone", "z", []string{}, "``timezone to show. Can be used multiple times. Defaults to the timezones in the config file.")
+	nowCmd.Flags().BoolVarP(&nowTwelveHour, "twelve-hour", "t", false, "use 12-hour time format instead of 24-hour. Defaults to the setting in the config file.")
+	err := nowCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
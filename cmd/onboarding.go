@@ -0,0 +1,83 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// shouldOnboard reports whether to run onboarding on the first run of cmd. It is skipped when TIMEBUDDY_SKIP_ONBOARDING
+// or --quiet is set, when timezones are given with --timezone or --timezone-file, or when stdin or stdout isn't a
+// terminal, so scripts and pipes never block on a prompt or get it mixed into their output.
+func shouldOnboard(cmd *cobra.Command) bool {
+	if os.Getenv("TIMEBUDDY_SKIP_ONBOARDING") != "" || quietEnabled {
+		return false
+	}
+	// called before the config file fills in the flags, so Changed means they were given on the command line
+	if cmd.Flags().Changed("timezone") || cmd.Flags().Changed("timezone-file") {
+		return false
+	}
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// promptTimezones asks for the timezones to show until every one of them loads, and returns them. An empty answer
+// returns no timezones.
+func promptTimezones(r *bufio.Reader, w io.Writer) []string {
+	for {
+		fmt.Fprint(w, "Time zones to show, separated by spaces, i.e. America/New_York Europe/London: ")
+		answer, err := r.ReadString('\n')
+		tzs := strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' || r == '\n' || r == '\r' })
+		var invalid []string
+		for _, tz := range tzs {
+			if _, err := loadLocation(tz); err != nil {
+				invalid = append(invalid, tz)
+			}
+		}
+		if len(invalid) == 0 || err != nil {
+			return tzs
+		}
+		fmt.Fprintf(w, "Unknown time zones: %s. Use timeBuddy search to find their names.\n", strings.Join(invalid, ", "))
+	}
+}
+
+// runOnboarding asks a first time user which timezones to show and creates the config file with them. If they decline,
+// a tip on the --timezone flag is printed and the config file is created with only the local timezone. The prompts are
+// written to stderr, so they never end up in the output.
+func runOnboarding(v *viper.Viper, log *zerolog.Logger) error {
+	r := bufio.NewReader(os.Stdin)
+	fmt.Fprintln(os.Stderr, "Welcome to timeBuddy! No config file was found, so this looks like your first run.")
+	fmt.Fprint(os.Stderr, "Would you like to select your preferred time zones? [Y/n]: ")
+	answer, _ := r.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	fv := viper.New()
+	if answer == "" || answer == "y" || answer == "yes" {
+		if tzs := promptTimezones(r, os.Stderr); len(tzs) > 0 {
+			fv.Set("timezone", tzs)
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, "Tip: add time zones with --timezone, i.e. timeBuddy --timezone America/New_York --timezone Europe/London")
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+		return err
+	}
+	log.Info().Str("configFile", getConfigPath()).Msg("New config file created:")
+	return v.ReadInConfig()
+}
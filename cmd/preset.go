@@ -0,0 +1,225 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var presetName string
+
+// timezonePreset is a named set of settings saved in the presets key of the config file: the timezones, whether color
+// and 12-hour time are enabled, and the working hours of each timezone.
+type timezonePreset struct {
+	Timezones    []string          `yaml:"timezone"`
+	Color        bool              `yaml:"color"`
+	TwelveHour   bool              `yaml:"twelve-hour"`
+	WorkingHours map[string]string `yaml:"working_hours,omitempty"`
+}
+
+// presetFromConfig returns the preset with the name from the viper instance, and whether it exists. Viper lowercases
+// map keys, so preset names are case-insensitive.
+func presetFromConfig(cv *viper.Viper, name string) (timezonePreset, bool) {
+	key := "presets." + strings.ToLower(name)
+	if !cv.IsSet(key) {
+		return timezonePreset{}, false
+	}
+	return timezonePreset{
+		Timezones:    cv.GetStringSlice(key + ".timezone"),
+		Color:        cv.GetBool(key + ".color"),
+		TwelveHour:   cv.GetBool(key + ".twelve-hour"),
+		WorkingHours: cv.GetStringMapString(key + ".working_hours"),
+	}, true
+}
+
+// workingHourOverrides returns the preset's working hours in the format taken by --working-hours, i.e.
+// America/New_York=09:00-17:00, sorted by timezone.
+func (p timezonePreset) workingHourOverrides() []string {
+	overrides := make([]string, 0, len(p.WorkingHours))
+	for tz, window := range p.WorkingHours {
+		overrides = append(overrides, tz+"="+window)
+	}
+	sort.Strings(overrides)
+	return overrides
+}
+
+// configuredPresets returns the names of the presets saved in the config file, sorted.
+func configuredPresets() []string {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	var names []string
+	for name := range fv.GetStringMap("presets") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completePresets completes the names of the presets saved in the config file.
+func completePresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return configuredPresets(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// readPreset reads the config file and returns it with the named preset, exiting if the preset isn't defined.
+func readPreset(name string) (*viper.Viper, timezonePreset) {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	p, ok := presetFromConfig(fv, name)
+	if !ok {
+		l.Fatal().Str("preset", name).Err(fmt.Errorf("preset is not defined")).Send()
+	}
+	return fv, p
+}
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage named sets of time zones and settings",
+	Long: `Manage presets, named sets of settings saved in the config file. Unlike a group or profile, which only hold time zones,
+a preset also holds whether color and 12-hour time are enabled, and the working hours of each time zone. Show a preset
+with timeBuddy --preset <name>, which doesn't change the settings saved in the config file, or make it the saved
+settings with preset load.
+
+Examples:
+
+  # Save the current settings as a preset, then show it:
+  $ timeBuddy preset save work
+  $ timeBuddy --preset work
+
+  # Share a preset with a teammate, who loads it with timeBuddy import:
+  $ timeBuddy preset export work > work.yaml
+  $ timeBuddy import work.yaml`,
+}
+
+var presetSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the current settings as a preset",
+	Long: `Save the time zones, color and 12-hour settings, and working hours in the config file as a preset. An existing preset
+with the same name is replaced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		if strings.ContainsAny(name, "./ ") {
+			l.Fatal().Str("preset", args[0]).Err(fmt.Errorf("preset name can't contain dots, slashes, or spaces")).Send()
+		}
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		tzs := fv.GetStringSlice("timezone")
+		if len(tzs) == 0 {
+			l.Fatal().Str("preset", args[0]).Err(fmt.Errorf("no timezones to save, run timeBuddy --timezone first")).Send()
+		}
+		preset := map[string]interface{}{
+			"timezone":    tzs,
+			"color":       fv.GetBool("color"),
+			"twelve-hour": fv.GetBool("twelve-hour"),
+		}
+		if wh := fv.GetStringMapString("working_hours"); len(wh) > 0 {
+			preset["working_hours"] = wh
+		}
+		fv.Set("presets."+name, preset)
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var presetLoadCmd = &cobra.Command{
+	Use:   "load <name>",
+	Short: "Replace the current settings with a preset",
+	Long: `Replace the time zones, color and 12-hour settings, and working hours in the config file with those of a preset, so
+they are used from now on. To show a preset once without changing the config file, use timeBuddy --preset <name>.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePresets,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, p := readPreset(args[0])
+		fv.Set("timezone", p.Timezones)
+		fv.Set("color", p.Color)
+		fv.Set("twelve-hour", p.TwelveHour)
+		if fv.IsSet("working_hours") {
+			if err := removeConfigKey(fv, "working_hours"); err != nil {
+				l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+			}
+		}
+		if len(p.WorkingHours) > 0 {
+			fv.Set("working_hours", p.WorkingHours)
+		}
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the presets and their time zones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		names := configuredPresets()
+		if len(names) == 0 {
+			fmt.Println("No presets saved.")
+			return
+		}
+		for _, name := range names {
+			p, _ := presetFromConfig(fv, name)
+			fmt.Printf("%s: %s (color: %t, twelve-hour: %t)\n", name, strings.Join(p.Timezones, ", "), p.Color, p.TwelveHour)
+		}
+	},
+}
+
+var presetDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a preset",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePresets,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		if err := removeConfigKey(fv, "presets."+strings.ToLower(args[0])); err != nil {
+			l.Fatal().Str("preset", args[0]).Err(fmt.Errorf("preset is not defined")).Send()
+		}
+	},
+}
+
+var presetExportCmd = &cobra.Command{
+	Use:               "export <name>",
+	Short:             "Print a preset as YAML to share it",
+	Long:              `Print a preset as YAML, under the presets key, so it can be added to another config file with timeBuddy import.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePresets,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, p := readPreset(args[0])
+		out := map[string]map[string]timezonePreset{"presets": {strings.ToLower(args[0]): p}}
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(out); err != nil {
+			l.Fatal().Err(err).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetSaveCmd, presetLoadCmd, presetListCmd, presetDeleteCmd, presetExportCmd)
+}
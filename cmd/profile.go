@@ -0,0 +1,138 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var profileTimezones []string
+
+// configuredProfiles returns the names of the profiles saved in the config file, sorted. Viper lowercases map keys, so
+// profile names are case-insensitive.
+func configuredProfiles() []string {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	var names []string
+	for name := range fv.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeProfiles completes the names of the profiles saved in the config file.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return configuredProfiles(), cobra.ShellCompDirectiveNoFileComp
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named sets of time zones",
+	Long: `Manage profiles, named sets of time zones saved in the config file, i.e. one for work and one for family. Show a
+profile with timeBuddy --profile <name>. Changes made while using a profile are saved to it, leaving the default set of
+time zones and other profiles alone.
+
+Examples:
+
+  # Save the time zones currently in the config file as a profile:
+  $ timeBuddy profile save work
+
+  # Save specific time zones as a profile, then show them:
+  $ timeBuddy profile save family --timezone Europe/Dublin --timezone Australia/Perth
+  $ timeBuddy --profile family`,
+}
+
+var profileSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save a set of time zones as a profile",
+	Long: `Save the time zones given with --timezone as a profile, or the time zones saved in the config file if none are
+given. An existing profile with the same name is replaced.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		if strings.ContainsAny(name, "./ ") {
+			l.Fatal().Str("profile", args[0]).Err(fmt.Errorf("profile name can't contain dots, slashes, or spaces")).Send()
+		}
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		tzs := profileTimezones
+		if len(tzs) == 0 {
+			tzs = fv.GetStringSlice("timezone")
+		}
+		if len(tzs) == 0 {
+			l.Fatal().Str("profile", args[0]).Err(fmt.Errorf("no timezones to save, use --timezone")).Send()
+		}
+		for _, tz := range tzs {
+			if _, err := time.LoadLocation(resolveAlias(tz)); err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+		}
+		fv.Set("profiles."+name, deduplicateSlice(tzs))
+		if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the profiles and their time zones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		names := configuredProfiles()
+		if len(names) == 0 {
+			fmt.Println("No profiles saved.")
+			return
+		}
+		for _, name := range names {
+			fmt.Printf("%s: %s\n", name, strings.Join(fv.GetStringSlice("profiles."+name), ", "))
+		}
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:               "delete <name>",
+	Short:             "Delete a profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfiles,
+	Run: func(cmd *cobra.Command, args []string) {
+		fv, err := readConfigFile()
+		if err != nil {
+			l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+		}
+		if err := removeConfigKey(fv, "profiles."+strings.ToLower(args[0])); err != nil {
+			l.Fatal().Str("profile", args[0]).Err(fmt.Errorf("profile is not defined")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileSaveCmd, profileListCmd, profileDeleteCmd)
+	profileSaveCmd.Flags().StringArrayVarP(&profileTimezones, "timezone", "z", []string{}, "``timezone to include in the profile. Can be used multiple times. Defaults to the timezones in the config file.")
+	if err := profileSaveCmd.Flags().SetAnnotation("timezone", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	err := profileSaveCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
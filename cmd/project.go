@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+// projectConfigKeys are the only keys a project config file may set. Keys like aliases and groups are left to the
+// user's config file, so a project directory can't redirect them.
+var projectConfigKeys = []string{"timezone", "color", "twelve-hour", "working_hours"}
+
+var (
+	noProjectConfig bool
+	// projectConfig holds the allowed keys of the project config file, or is nil if there is none
+	projectConfig *viper.Viper
+	// projectFlags are the flags filled in from the project config file, which aren't saved to the user's config file
+	projectFlags = map[string]bool{}
+	// envFlags are the flags filled in from environment variables, which aren't saved to the config file either
+	envFlags = map[string]bool{}
+)
+
+// findProjectConfig looks for a .timeBuddy.yaml in the working directory and each directory above it, stopping before
+// $HOME or at the filesystem root, and returns the path of the first one found.
+func findProjectConfig() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	home := filepath.Clean(os.Getenv("HOME"))
+	for dir != home {
+		path := filepath.Join(dir, configName+"."+configType)
+		if _, err := os.Stat(path); err == nil && path != getConfigPath() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", false
+}
+
+// loadProjectConfig reads the project config file, if there is one and --no-project-config wasn't provided, into
+// projectConfig. Keys a project config file can't set are dropped with a warning to log.
+func loadProjectConfig(log *zerolog.Logger) error {
+	path, ok := findProjectConfig()
+	if noProjectConfig || !ok {
+		return nil
+	}
+	fv := viper.New()
+	fv.SetConfigFile(path)
+	fv.SetConfigType(configType)
+	if err := fv.ReadInConfig(); err != nil {
+		return err
+	}
+
+	projectConfig = viper.New()
+	var ignored []string
+	for key, val := range fv.AllSettings() {
+		if slices.Contains(projectConfigKeys, key) {
+			projectConfig.Set(key, val)
+		} else {
+			ignored = append(ignored, key)
+		}
+	}
+	log.Info().Str("projectConfig", path).Msg("Using project config file:")
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		log.Warn().Str("projectConfig", path).Strs("keys", ignored).Msg("Ignoring keys a project config file can't set:")
+	}
+	return nil
+}
+
+// projectSetting returns the value of a key in the project config file, and whether it is set there.
+func projectSetting(key string) (interface{}, bool) {
+	if projectConfig == nil || !projectConfig.IsSet(key) {
+		return nil, false
+	}
+	return projectConfig.Get(key), true
+}
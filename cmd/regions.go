@@ -0,0 +1,108 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// defaultGroupOffsets are the UTC offset ranges --group-regions groups timezones by, unless --group-offsets is provided
+const defaultGroupOffsets = "-12:-4,-3:3,4:8,9:14"
+
+// offsetRange is a range of whole hour UTC offsets, including both ends, and the name of the region it covers
+type offsetRange struct {
+	from, to int
+	label    string
+}
+
+// defaultRegionLabels name the ranges in defaultGroupOffsets. Other ranges are labeled with their offsets.
+var defaultRegionLabels = map[[2]int]string{
+	{-12, -4}: "Americas",
+	{-3, 3}:   "Atlantic/Europe/Africa",
+	{4, 8}:    "Middle East/Asia",
+	{9, 14}:   "Pacific",
+}
+
+var (
+	groupRegionsEnabled bool
+	groupOffsets        string
+	// regionRanges are the ranges parsed from --group-offsets
+	regionRanges []offsetRange
+)
+
+// regionGroup is a run of consecutive timezones in the same region
+type regionGroup struct {
+	label string
+	zones timezoneDetails
+}
+
+// parseGroupOffsets parses --group-offsets, a comma separated list of UTC offset ranges like -12:-4,-3:3. A minus sign
+// may also be written as −, as it often is when copied from a web page.
+func parseGroupOffsets(s string) ([]offsetRange, error) {
+	var ranges []offsetRange
+	for _, part := range strings.Split(strings.ReplaceAll(s, "−", "-"), ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid offset range %q, expected a format like -3:3", part)
+		}
+		r := offsetRange{}
+		var err error
+		if r.from, err = strconv.Atoi(from); err != nil {
+			return nil, fmt.Errorf("invalid offset range %q, expected a format like -3:3", part)
+		}
+		if r.to, err = strconv.Atoi(to); err != nil {
+			return nil, fmt.Errorf("invalid offset range %q, expected a format like -3:3", part)
+		}
+		if r.from < -12 || r.to > 14 || r.from > r.to {
+			return nil, fmt.Errorf("invalid offset range %q, offsets must be -12 to 14 and the first can't be after the second", part)
+		}
+		r.label = defaultRegionLabels[[2]int{r.from, r.to}]
+		if r.label == "" {
+			r.label = fmt.Sprintf("UTC%+d to UTC%+d", r.from, r.to)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// regionLabel returns the label of the first range holding the timezone's offset, truncated to whole hours, or Other
+// if none does.
+func regionLabel(z timezoneDetail) string {
+	for _, r := range regionRanges {
+		if z.offset >= r.from && z.offset <= r.to {
+			return r.label
+		}
+	}
+	return "Other"
+}
+
+// groupZonesByRegion splits the zones into runs of consecutive zones in the same region. The zones keep their order, so
+// a region appears more than once if its zones aren't listed together.
+func groupZonesByRegion(zones timezoneDetails) []regionGroup {
+	var groups []regionGroup
+	for _, z := range zones {
+		label := regionLabel(z)
+		if len(groups) == 0 || groups[len(groups)-1].label != label {
+			groups = append(groups, regionGroup{label: label})
+		}
+		groups[len(groups)-1].zones = append(groups[len(groups)-1].zones, z)
+	}
+	return groups
+}
+
+// appendRegionRow appends a row holding only the region label, in the first column, to the table. The row has a cell
+// for every column, so the index column and highlighted columns line up with the other rows.
+func appendRegionRow(t table.Writer, label string, columns int) {
+	row := make(table.Row, columns)
+	row[0] = text.Bold.Sprint(label)
+	for i := 1; i < columns; i++ {
+		row[i] = ""
+	}
+	t.AppendRow(row)
+}
@@ -4,17 +4,31 @@ Copyright © 2024 Jake Rogers <code@supportoss.org>
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"runtime"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"text/template"
 	"time"
 	_ "time/tzdata"
 
+	"github.com/JakeTRogers/timeBuddy/internal/buildinfo"
 	"github.com/JakeTRogers/timeBuddy/logger"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -22,61 +36,279 @@ import (
 
 var (
 	colorEnabled               bool
+	emojiEnabled               bool
+	iconsEnabled               bool
+	noIcon                     bool
+	noEmoji                    bool
+	numberedEnabled            bool
+	relativeColumnEnabled      bool
+	followLinks                bool
+	shadeEnabled               bool
+	nightColor                 string
+	dawnColor                  string
+	dayColor                   string
+	shadeColors                dayNightColors
+	suggestEnabled             bool
+	relativeTo                 string
+	workingHoursFlag           []string
+	workingHours               map[string][2]int // keyed by lowercase timezone name
+	onlyRows                   []int
+	withoutTimezones           []string
+	timezoneFile               string
 	twelveHourEnabled          bool
+	ampmStyle                  string
+	ampmMarkers                = [2]string{"am", "pm"}
 	date                       string
+	timeOfDay                  string
+	unixTime                   string
+	specifiedHour              int
+	specifiedMinute            int
+	format                     string
 	timezones                  []string
+	timezoneFlagChanged        bool
+	colorFlagChanged           bool
+	twelveHourFlagChanged      bool
+	highlightFlagChanged       bool
+	saveHighlight              bool
+	highlightNowEnabled        bool
+	outputFile                 string
+	ignoreErrors               bool
+	noLocal                    bool
+	localeDate                 bool
+	nowOverride                string
+	dateHighlight              string // derived from a date and time in --date, in local time, i.e. 04:00L
+	rangeFrom                  string
+	rangeTo                    string
+	rangeDates                 []string // the days between --from and --to, as YYYY-MM-DD
+	appendOutput               bool
+	profile                    string
+	logFormat                  string
+	logFile                    string
+	quietEnabled               bool
+	configFile                 string
 	v                          = viper.New()
 	l                          = logger.GetLogger()
 	replaceHyphenWithCamelCase = false
+	// dayChangeMarker is prefixed to the hour cell where the day changes in a timezone
+	dayChangeMarker = "▏"
+	// legacyFlagAliases maps flag spellings used by similar tools to their canonical timeBuddy flag names
+	legacyFlagAliases = map[string]string{
+		"tz":  "timezone",
+		"12h": "twelve-hour",
+		"hl":  "highlight",
+	}
+	// legacyFlagsUsed are the legacy spellings used on the command line, in the order they were first used
+	legacyFlagsUsed []string
+	// renamedFlags maps the old names of renamed flags to their new names. The old names still work, in the config
+	// file too, and are only noted at warn verbosity.
+	renamedFlags = map[string]string{
+		"exclude-local": "no-local",
+	}
+	renamedFlagsUsed = map[string]bool{}
+)
+
+const (
+	// configName is the name of a project config file, and of the config file before it moved to configDirName
+	configName     = ".timeBuddy"
+	configDirName  = "timebuddy"
+	configFileName = "config"
+	configType     = "yaml"
 )
 
-type timezoneDetail = struct {
+// skipConfigAnnotation marks a flag that must not be populated from the config file, i.e. a single value --timezone
+// flag on a subcommand that would otherwise receive the list of configured timezones.
+const skipConfigAnnotation = "timebuddy_skip_config"
+
+type timezoneDetail struct {
+	index          int // 1-based position of the timezone in the resolved list, used by --numbered and --only
 	name           string
 	abbreviation   string
 	currentTime    time.Time
 	offset         int
+	offsetMinutes  int // full offset in minutes east of UTC, unlike offset which is truncated to whole hours
 	halfHourOffset bool
+	hasWorkHours   bool
+	workStart      int // start of working hours in minutes since local midnight
+	workEnd        int // end of working hours in minutes since local midnight
 	hours          []int
+	hourTimes      []time.Time
+	icon           string // flag emoji of the timezone's country, or the icon of the alias it was given as
+	// relativeBaseMinutes is the offset of the --relative-to timezone at currentTime, in minutes east of UTC. It is
+	// looked up with the timezone, so a bad --relative-to is reported before the table is rendered.
+	relativeBaseMinutes int
 }
 
 type timezoneDetails = []timezoneDetail
 
+// Name returns the timezone name, i.e. America/New_York. It is exposed for use in --format templates.
+func (z timezoneDetail) Name() string {
+	return z.name
+}
+
+// Abbrev returns the timezone abbreviation, i.e. EST. It is exposed for use in --format templates.
+func (z timezoneDetail) Abbrev() string {
+	return z.abbreviation
+}
+
+// Time returns the current time in the timezone. It is exposed for use in --format templates.
+func (z timezoneDetail) Time() time.Time {
+	return z.currentTime
+}
+
+// Offset returns the formatted UTC offset of the timezone, i.e. +5.5. It is exposed for use in --format templates.
+func (z timezoneDetail) Offset() string {
+	return formatOffset(z)
+}
+
+// RelativeOffsetMinutes returns the difference in minutes between the timezone and the reference timezone, local by
+// default or the one set with --relative-to. It is exposed for use in --format templates.
+func (z timezoneDetail) RelativeOffsetMinutes() int {
+	return z.offsetMinutes - z.relativeBaseMinutes
+}
+
+// configFileOverride returns the config file set with --config, or else TIMEBUDDY_CONFIG, as an absolute path so it can
+// be compared with the paths of file events. An empty string means the default config file is used.
+func configFileOverride() string {
+	path := configFile
+	if path == "" {
+		path = os.Getenv("TIMEBUDDY_CONFIG")
+	}
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// getConfigDir returns the directory holding the config file. The directory of --config or TIMEBUDDY_CONFIG if set,
+// otherwise a timebuddy directory in the user's config directory, i.e. $XDG_CONFIG_HOME or $HOME/.config on Linux,
+// $HOME/Library/Application Support on macOS, and %APPDATA% on Windows.
+func getConfigDir() string {
+	if path := configFileOverride(); path != "" {
+		return filepath.Dir(path)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, configDirName)
+}
+
+// getConfigPath returns the full path of the config file.
+func getConfigPath() string {
+	if path := configFileOverride(); path != "" {
+		return path
+	}
+	return filepath.Join(getConfigDir(), configFileName+"."+configType)
+}
+
+// setupLogging applies the --verbose, --quiet, --log-format, and --log-file flags to the logger.
+func setupLogging(cmd *cobra.Command) error {
+	verboseCount, _ := cmd.Flags().GetCount("verbose")
+	logger.SetLogLevel(verboseCount)
+	// --verbose wins over --quiet, so a quiet alias can still be debugged
+	if quietEnabled && verboseCount == 0 {
+		logger.SetQuiet()
+	} else {
+		quietEnabled = false
+	}
+	if err := logger.SetFormat(logFormat); err != nil {
+		return err
+	}
+	if logFile != "" {
+		// the file is left open for the life of the process, since anything may log until it exits
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logger.SetOutput(f)
+	}
+	// flags are parsed before --quiet and the log level are known, so legacy and renamed flags are noted here, only
+	// with --verbose
+	if !quietEnabled && verboseCount > 0 {
+		for _, name := range legacyFlagsUsed {
+			fmt.Fprintln(os.Stderr, text.Colors{text.Faint}.Sprintf("Notice: --%s is a legacy spelling, use --%s instead.", name, legacyFlagAliases[name]))
+		}
+	}
+	for name := range renamedFlagsUsed {
+		l.Warn().Str("flag", "--"+name).Str("replacement", "--"+renamedFlags[name]).Msg("Flag has been renamed:")
+	}
+	return nil
+}
+
 // initializeConfig initializes the configuration for the root command.
 // It sets up the configuration file path, reads the config file if it exists,
 // creates a new config file if it doesn't exist, and binds command flags to environment variables.
-// The function takes a pointer to the root command as a parameter and returns an error.
-func initializeConfig(cmd *cobra.Command) error {
-	verboseCount, _ := cmd.Flags().GetCount("verbose")
-	logger.SetLogLevel(verboseCount)
-	configName := ".timeBuddy"
-	v.SetConfigName(configName)
-	configType := "yaml"
+// The function takes a pointer to the root command, the viper instance to read the config into, and the logger to
+// report to, which are passed on to the helpers it calls, and returns an error. The config file is still the one
+// returned by getConfigPath, and the flags filled in from the project config file or the environment are recorded in
+// projectFlags and envFlags, so a test sets configFile and resets those first.
+func initializeConfig(cmd *cobra.Command, v *viper.Viper, log *zerolog.Logger) error {
+	if err := setupLogging(cmd); err != nil {
+		return err
+	}
 	v.SetConfigType(configType)
-	configPath := ""
-	if runtime.GOOS == "windows" {
-		configPath = os.Getenv("APPDATA")
+	// the directory of a file given with --config or TIMEBUDDY_CONFIG is created too, so the file can be
+	configPath := getConfigDir()
+	if err := os.MkdirAll(configPath, 0o755); err != nil {
+		log.Error().Str("configPath", configPath).Err(err).Send()
+	}
+	if path := configFileOverride(); path != "" {
+		// use exactly the file given, whatever its name
+		log.Debug().Str("configFile", path).Send()
+		v.SetConfigFile(path)
 	} else {
-		configPath = filepath.Join(os.Getenv("HOME"), ".config")
+		// move a config file from where older versions kept it, so its settings aren't lost
+		if moved, err := moveLegacyConfig(); err != nil {
+			log.Error().Str("configFile", legacyConfigPath()).Err(err).Send()
+		} else if moved {
+			log.Info().Str("from", legacyConfigPath()).Str("to", getConfigPath()).Msg("Moved config file:")
+		}
+		v.SetConfigName(configFileName)
+		log.Debug().Str("configPath", configPath).Send()
+		v.AddConfigPath(configPath)
 	}
-	l.Debug().Str("configPath", configPath).Send()
-	v.AddConfigPath(configPath)
+
+	// check for the config file before reading it, so a first run can be told apart from a config that won't load
+	_, statErr := os.Stat(getConfigPath())
+	firstRun := errors.Is(statErr, fs.ErrNotExist)
 
 	// Attempt to read the config file
 	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		// a file set with SetConfigFile that doesn't exist is reported as a missing file, rather than not found
+		_, ok := err.(viper.ConfigFileNotFoundError)
+		ok = ok || errors.Is(err, fs.ErrNotExist)
+		if ok && firstRun && !cmd.HasParent() && shouldOnboard(cmd) {
+			if err := runOnboarding(v, log); err != nil {
+				log.Error().Err(err).Send()
+			}
+		} else if ok {
 			// Create config file if it doesn't exist
-			if err := v.SafeWriteConfig(); err != nil {
-				l.Error().Err(err).Send()
+			if err := atomicWriteConfig(v, getConfigPath()); err != nil {
+				log.Error().Err(err).Send()
 			}
-			l.Info().Str("configFile", filepath.Join(configPath, configName+"."+configType)).Msg("New config file created:")
+			log.Info().Str("configFile", getConfigPath()).Msg("New config file created:")
 		} else {
 			// Config file was found but another error was produced
-			l.Error().Str("viper", err.Error()).Send()
+			log.Error().Str("viper", err.Error()).Send()
+		}
+	} else if migrated, err := migrateConfig(log); err != nil {
+		log.Error().Str("configFile", getConfigPath()).Err(err).Send()
+	} else if migrated {
+		// reload the migrated file so this run sees the new schema
+		if err := v.ReadInConfig(); err != nil {
+			log.Error().Str("viper", err.Error()).Send()
 		}
 	}
 
+	if err := loadProjectConfig(log); err != nil {
+		log.Error().Err(err).Send()
+	}
+
 	// When we bind flags to environment variables expect that the environment variables are prefixed, e.g. a flag like
-	// --timezones binds to an environment variable TIMEBUDDY_TIMEZONES. This helps avoid conflicts.
+	// --twelve-hour binds to an environment variable TIMEBUDDY_TWELVE_HOUR. This helps avoid conflicts.
 	v.SetEnvPrefix("TIMEBUDDY")
 
 	// Environment variables can't have dashes in them, so bind them to their equivalent keys with underscores
@@ -86,19 +318,48 @@ func initializeConfig(cmd *cobra.Command) error {
 	// those in the bindFlags function
 	v.AutomaticEnv()
 
+	// --timezone takes a list, so the plural TIMEBUDDY_TIMEZONES is accepted as well as TIMEBUDDY_TIMEZONE
+	if err := v.BindEnv("timezone", "TIMEBUDDY_TIMEZONE", "TIMEBUDDY_TIMEZONES"); err != nil {
+		log.Error().Err(err).Send()
+	}
+
 	// Bind the current command's flags to viper
-	bindFlags(cmd, v)
+	bindFlags(cmd, v, log)
 
 	return nil
 }
 
+// normalizeLegacyFlags is a pflag normalization function that maps legacy flag spellings to their canonical names.
+// Because the alias is resolved before the flag is looked up, it behaves exactly like the canonical flag, including
+// config binding and persistence. Each alias used is recorded, so setupLogging can print a dim notice suggesting the
+// canonical spelling to stderr with --verbose.
+func normalizeLegacyFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if canonical, ok := legacyFlagAliases[name]; ok {
+		if !slices.Contains(legacyFlagsUsed, name) {
+			legacyFlagsUsed = append(legacyFlagsUsed, name)
+		}
+		name = canonical
+	}
+	if newName, ok := renamedFlags[name]; ok {
+		renamedFlagsUsed[name] = true
+		name = newName
+	}
+	return pflag.NormalizedName(name)
+}
+
 // bindFlags binds the command flags to the corresponding values in the viper configuration.
 // It iterates over each flag, determines the naming convention of the flag in the config file,
 // and applies the corresponding value from the viper configuration to the flag if it is not already set.
-// If the value is an array, it loops through each element and adds it to the flag.
-func bindFlags(cmd *cobra.Command, v *viper.Viper) {
+// If the value is an array, it loops through each element and adds it to the flag. A list flag given a string, i.e. from
+// an environment variable, is split with splitEnvList first.
+// Flags annotated with skipConfigAnnotation are left alone. Binding is logged to the given logger.
+func bindFlags(cmd *cobra.Command, v *viper.Viper, log *zerolog.Logger) {
 
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[skipConfigAnnotation]; ok {
+			return
+		}
+
 		// Determine the naming convention of the flags when represented in the config file
 		configName := f.Name
 		// If using camelCase in the config file, replace hyphens with a camelCased string.
@@ -107,57 +368,457 @@ func bindFlags(cmd *cobra.Command, v *viper.Viper) {
 			configName = strings.ReplaceAll(f.Name, "-", "")
 		}
 
-		// Apply the viper config value to the flag when the flag is not set and viper has a value
-		l.Debug().Str("flag", f.Name).Str("configName", configName).Msg("Binding flag to viper config:")
-		if !f.Changed && v.IsSet(configName) {
-			val := v.Get(configName)
+		// the config file and environment may still use the flag's old name, or a legacy spelling of it
+		if !v.IsSet(configName) {
+			for _, names := range []map[string]string{renamedFlags, legacyFlagAliases} {
+				for oldName, newName := range names {
+					if newName == f.Name && v.IsSet(oldName) {
+						configName = oldName
+					}
+				}
+			}
+		}
+
+		// Apply the viper config value to the flag when the flag is not set and viper has a value. A value in the project
+		// config file takes precedence.
+		log.Debug().Str("flag", f.Name).Str("configName", configName).Msg("Binding flag to viper config:")
+		val, fromProject := projectSetting(configName)
+		if !f.Changed && (fromProject || v.IsSet(configName)) {
+			if fromProject {
+				projectFlags[f.Name] = true
+			} else {
+				val = v.Get(configName)
+				if setInEnv(configName) {
+					envFlags[f.Name] = true
+				}
+			}
+			// a list from an environment variable is a string, i.e. TIMEBUDDY_TIMEZONES="UTC,Europe/Berlin"
+			if str, ok := val.(string); ok {
+				if _, isSlice := f.Value.(pflag.SliceValue); isSlice {
+					val = splitEnvList(f.Name, str)
+				}
+			}
 			// if the value is an array, loop through it and add each value
 			if arr, ok := val.([]interface{}); ok {
 				for _, v := range arr {
 					if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", v)); err != nil {
-						l.Error().Str("viper", err.Error()).Send()
+						log.Error().Str("viper", err.Error()).Send()
 					}
 				}
 			} else {
 				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
-					l.Error().Str("viper", err.Error()).Send()
+					log.Error().Str("viper", err.Error()).Send()
 				}
 			}
 		}
 	})
 }
 
+// splitEnvList splits a list given in an environment variable for the flag into its values. Values are separated by
+// commas, and timezones may also be separated by colons, like PATH, except within a UTC offset like UTC+05:30.
+func splitEnvList(flag, s string) []interface{} {
+	var values []interface{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		fields := []string{part}
+		if flag == "timezone" && !offsetZonePattern.MatchString(part) {
+			fields = strings.Split(part, ":")
+		}
+		for _, field := range fields {
+			if field = strings.TrimSpace(field); field != "" {
+				values = append(values, field)
+			}
+		}
+	}
+	return values
+}
+
 // getZoneInfo returns the timezone details for a given timezone and date.
-// It takes a timezone string and a date string as input and returns a timezoneDetail struct.
+// It takes a context, a timezone string, a date string, and the logger to report to as input and returns a timezoneDetail
+// struct, or an error if the timezone is invalid or the context is done.
 // The timezoneDetail struct contains information such as the timezone name, time, abbreviation, offset, and hours for the timezone.
-func getZoneInfo(timezone string, date string) timezoneDetail {
+func getZoneInfo(ctx context.Context, timezone string, date string, log *zerolog.Logger) (timezoneDetail, error) {
 	var zone timezoneDetail
+	if err := ctx.Err(); err != nil {
+		return zone, err
+	}
 
-	// validate timezone
-	loc, err := time.LoadLocation(timezone)
+	// validate timezone, after resolving aliases, names typed in a different case, and city names
+	name := timezone
+	timezone = resolveAlias(timezone)
+	if canonical, ok := matchTimezoneName(timezone); ok {
+		timezone = canonical
+	}
+	loc, err := loadLocation(timezone)
 	if err != nil {
-		l.Fatal().Str("timezone", timezone).Err(err).Send()
+		city, ok, cityErr := resolveCity(timezone)
+		if cityErr != nil {
+			return zone, cityErr
+		}
+		if !ok {
+			return zone, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		timezone = city
+		if loc, err = loadLocation(timezone); err != nil {
+			return zone, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
 	}
+	zone.icon = timezoneIcon(name, timezone)
 	zone.name = timezone
-	// if date == today, use current time, otherwise use midnight
-	if date == time.Now().Format(time.DateOnly) {
-		zone.currentTime = time.Now().Local().In(loc)
+	// name a UTC offset after the offset, i.e. UTC+5:30 for +05:30
+	if offsetZonePattern.MatchString(timezone) {
+		zone.name = loc.String()
+	}
+	// show the canonical name of links, i.e. America/New_York rather than US/Eastern
+	if canonical, ok := resolveTimezoneLink(timezone); ok && followLinks {
+		log.Warn().Str("timezone", timezone).Str("canonical", canonical).Msg("Timezone is a link, using its canonical name:")
+		zone.name = canonical
+	}
+	// if a time was specified, use it. Otherwise, if date == today, use current time, otherwise use midnight
+	if timeOfDay != "" {
+		zone.currentTime = specifiedTime(date).In(loc)
+	} else if date == timeNow().Format(time.DateOnly) {
+		zone.currentTime = timeNow().Local().In(loc)
 	} else {
 		d, _ := time.Parse(time.DateOnly, date)
 		zone.currentTime = time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), loc)
 	}
-	zone.abbreviation, zone.offset = zone.currentTime.In(loc).Zone()
+	zone.abbreviation, zone.offset = zoneAt(timezone, loc, zone.currentTime)
 	zone.halfHourOffset = zone.offset%3600 != 0
+	zone.offsetMinutes = zone.offset / 60
 	zone.offset = zone.offset / 3600 // convert offset from seconds east of UTC to hours
-	l.Debug().Str("timezone", zone.name).Str("abbreviation", zone.abbreviation).Str("currentTime", zone.currentTime.String()).Int("offset", zone.offset).Send()
+	if zone.relativeBaseMinutes, err = referenceOffsetMinutes(zone.currentTime); err != nil {
+		return zone, fmt.Errorf("invalid relative-to timezone %q: %w", relativeTo, err)
+	}
+	log.Debug().Str("timezone", zone.name).Str("abbreviation", zone.abbreviation).Str("currentTime", zone.currentTime.String()).Int("offset", zone.offset).Send()
+
+	if wh, ok := workingHours[strings.ToLower(timezone)]; ok {
+		zone.hasWorkHours = true
+		zone.workStart, zone.workEnd = wh[0], wh[1]
+	}
 
-	// get hours for the timezone
-	hours := getHours(zone.currentTime, loc)
+	// get hours for the timezone. The table columns are the hours of a UTC day, so every zone must use the same UTC
+	// date rather than its own local date, otherwise the day names would be off by one for zones west or east of UTC
+	tableDay := timeNow().UTC()
+	if timeOfDay != "" {
+		tableDay = specifiedTime(date).UTC()
+	} else if date != timeNow().Format(time.DateOnly) {
+		tableDay, _ = time.Parse(time.DateOnly, date)
+	}
+	hours := getHours(tableDay, loc)
 	for _, h := range hours {
 		zone.hours = append(zone.hours, h.Hour())
 	}
+	zone.hourTimes = hours
+
+	return zone, nil
+}
+
+// processTimezones returns the details of each timezone on the date, in the same order as the timezones. Each
+// timezone is looked up in its own goroutine, since loading tzdata for many timezones is slow when done one at a time.
+// No more timezones are looked up once the context is done, i.e. on Ctrl+C, and its error is returned. Otherwise the
+// error of the first timezone that fails is returned.
+func processTimezones(ctx context.Context, tzs []string, date string, log *zerolog.Logger) (timezoneDetails, error) {
+	zones := make(timezoneDetails, len(tzs))
+	errs := make([]error, len(tzs))
+	var wg sync.WaitGroup
+	for i, tz := range tzs {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, tz string) {
+			defer wg.Done()
+			zone, err := getZoneInfo(ctx, tz, date, log)
+			zone.index = i + 1
+			zones[i], errs[i] = zone, err
+		}(i, tz)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return zones, nil
+}
+
+// processTimezonesLenient is processTimezones for --ignore-errors. It returns the details of the timezones that load,
+// in the same order as the timezones and numbered as if the others weren't there, along with an error for each one that
+// doesn't. No more timezones are looked up once the context is done, so the caller should check its error.
+func processTimezonesLenient(ctx context.Context, tzs []string, date string, log *zerolog.Logger) (timezoneDetails, []error) {
+	loaded := make(timezoneDetails, len(tzs))
+	errs := make([]error, len(tzs))
+	var wg sync.WaitGroup
+	for i, tz := range tzs {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, tz string) {
+			defer wg.Done()
+			loaded[i], errs[i] = getZoneInfo(ctx, tz, date, log)
+		}(i, tz)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil
+	}
+	var zones timezoneDetails
+	var zoneErrs []error
+	for i, zone := range loaded {
+		if errs[i] != nil {
+			zoneErrs = append(zoneErrs, errs[i])
+			continue
+		}
+		zone.index = len(zones) + 1
+		zones = append(zones, zone)
+	}
+	return zones, zoneErrs
+}
+
+// exitOnZoneError exits if loading the timezones failed. Loading cancelled with Ctrl+C exits quietly, with the status a
+// shell gives a command interrupted by it.
+func exitOnZoneError(err error) {
+	if errors.Is(err, context.Canceled) {
+		os.Exit(130)
+	}
+	if err != nil {
+		l.Fatal().Err(err).Send()
+	}
+}
+
+// splitTimezoneList splits comma separated timezones in each value, keeping their order, so -z A -z B,C is A, B, C.
+// Empty entries, i.e. from a trailing comma, are dropped.
+func splitTimezoneList(values []string) []string {
+	var tzs []string
+	for _, val := range values {
+		for _, tz := range strings.Split(val, ",") {
+			if tz = strings.TrimSpace(tz); tz != "" {
+				tzs = append(tzs, tz)
+			}
+		}
+	}
+	return tzs
+}
+
+// readTimezoneFile reads newline separated timezones from a file, or stdin if path is -. Blank lines and comments,
+// which start with #, are skipped. Each timezone is validated, and an error names the line it is on.
+func readTimezoneFile(path string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	name := "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r, name = f, path
+	}
+
+	var tzs []string
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		tz, _, _ := strings.Cut(scanner.Text(), "#")
+		tz = strings.TrimSpace(tz)
+		if tz == "" {
+			continue
+		}
+		resolved, err := resolveTimezoneNames([]string{tz})
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, lineNum, err)
+		}
+		if _, err := loadLocation(resolveAlias(resolved[0])); err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid timezone %q: %w", name, lineNum, tz, err)
+		}
+		tzs = append(tzs, tz)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tzs, nil
+}
+
+// excludeTimezones returns the timezones not listed in without, along with the names in without that aren't in the
+// list. A timezone matches if it, or what it resolves to as an alias or city, is the same as a name in without.
+func excludeTimezones(tzs, without []string) ([]string, []string) {
+	resolve := func(tz string) string {
+		if resolved, err := resolveTimezoneNames([]string{tz}); err == nil {
+			tz = resolved[0]
+		}
+		return resolveAlias(tz)
+	}
+	excluded := make(map[string]bool, len(without))
+	for _, tz := range without {
+		excluded[resolve(tz)] = false
+	}
+	var kept []string
+	for _, tz := range tzs {
+		if _, ok := excluded[resolve(tz)]; ok {
+			excluded[resolve(tz)] = true
+			continue
+		}
+		kept = append(kept, tz)
+	}
+	var unknown []string
+	for _, tz := range without {
+		if !excluded[resolve(tz)] {
+			unknown = append(unknown, tz)
+		}
+	}
+	return kept, unknown
+}
+
+// parseWorkingHours parses a working hours window in the format "HH:MM-HH:MM", i.e. 09:00-17:30.
+// It returns the start and end of the window in minutes since midnight.
+func parseWorkingHours(s string) ([2]int, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return [2]int{}, fmt.Errorf("invalid working hours %q, expected a format like 09:00-17:00", s)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(startStr))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("invalid working hours %q, expected a format like 09:00-17:00", s)
+	}
+	// 24:00 can't be parsed as a time of day, but is a valid end of a working day
+	endMinutes := 24 * 60
+	if strings.TrimSpace(endStr) != "24:00" {
+		end, err := time.Parse("15:04", strings.TrimSpace(endStr))
+		if err != nil {
+			return [2]int{}, fmt.Errorf("invalid working hours %q, expected a format like 09:00-17:00", s)
+		}
+		endMinutes = end.Hour()*60 + end.Minute()
+	}
+	startMinutes := start.Hour()*60 + start.Minute()
+	if startMinutes >= endMinutes {
+		return [2]int{}, fmt.Errorf("invalid working hours %q, start must be before end", s)
+	}
+	return [2]int{startMinutes, endMinutes}, nil
+}
+
+// loadWorkingHours returns the working hours for each timezone, keyed by lowercase timezone name. It reads the
+// working_hours map from the config file and applies any overrides, which are in the format timezone=HH:MM-HH:MM.
+// Viper lowercases map keys, so the timezone names are lowercased to match.
+func loadWorkingHours(overrides []string) (map[string][2]int, error) {
+	hours := make(map[string][2]int)
+	for tz, window := range v.GetStringMapString("working_hours") {
+		wh, err := parseWorkingHours(window)
+		if err != nil {
+			return nil, fmt.Errorf("working_hours for %s: %w", tz, err)
+		}
+		hours[strings.ToLower(tz)] = wh
+	}
+	// working hours in the project config file replace those of the same timezone in the user's
+	if projectConfig != nil {
+		for tz, window := range projectConfig.GetStringMapString("working_hours") {
+			wh, err := parseWorkingHours(window)
+			if err != nil {
+				return nil, fmt.Errorf("working_hours for %s in the project config file: %w", tz, err)
+			}
+			hours[strings.ToLower(tz)] = wh
+		}
+	}
+	for _, val := range overrides {
+		tz, window, ok := strings.Cut(val, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid working hours %q, expected a format like America/New_York=09:00-17:00", val)
+		}
+		wh, err := parseWorkingHours(window)
+		if err != nil {
+			return nil, err
+		}
+		hours[strings.ToLower(tz)] = wh
+	}
+	return hours, nil
+}
+
+// defaultWorkingHours are the working hours of a timezone without its own, 09:00-17:00 in minutes since local midnight
+var defaultWorkingHours = [2]int{9 * 60, 17 * 60}
+
+// zoneWorkingHours returns the working hours of the zone in minutes since local midnight, or defaultHours if it has none
+// set with working_hours or --working-hours.
+func zoneWorkingHours(z timezoneDetail, defaultHours [2]int) [2]int {
+	if z.hasWorkHours {
+		return [2]int{z.workStart, z.workEnd}
+	}
+	return defaultHours
+}
+
+// inWorkingHours reports whether the given number of minutes starting at localMinute, in minutes since local midnight,
+// fit entirely inside the working hours. It is shared by meet, suggest, and the call window, so they agree on what's in
+// hours.
+func inWorkingHours(localMinute, length int, hours [2]int) bool {
+	return localMinute >= hours[0] && localMinute+length <= hours[1]
+}
+
+// loadWorkingHoursFlag returns the default working hours and the working hours of each timezone, for subcommands whose
+// --working-hours flag also takes a value without a timezone, i.e. 08:30-16:30, to change the default for every
+// timezone. The default is defaultWorkingHours. The values with a timezone are passed to loadWorkingHours.
+func loadWorkingHoursFlag(values []string) ([2]int, map[string][2]int, error) {
+	defaultHours := defaultWorkingHours
+	var overrides []string
+	for _, val := range values {
+		if strings.Contains(val, "=") {
+			overrides = append(overrides, val)
+			continue
+		}
+		wh, err := parseWorkingHours(val)
+		if err != nil {
+			return defaultHours, nil, err
+		}
+		defaultHours = wh
+	}
+	hours, err := loadWorkingHours(overrides)
+	return defaultHours, hours, err
+}
+
+// maxAMPMMarkerWidth is the widest am/pm marker, in display cells, that fits in an hour column without crowding it
+const maxAMPMMarkerWidth = 3
+
+// parseAMPMStyle returns the am and pm markers for an am/pm style. The style is lower (am/pm), upper (AM/PM), single
+// (a/p), or a custom pair separated by a slash, i.e. vm/nm. Markers wider than maxAMPMMarkerWidth display cells are
+// rejected.
+func parseAMPMStyle(style string) ([2]string, error) {
+	var markers [2]string
+	switch strings.ToLower(strings.TrimSpace(style)) {
+	case "", "lower":
+		markers = [2]string{"am", "pm"}
+	case "upper":
+		markers = [2]string{"AM", "PM"}
+	case "single":
+		markers = [2]string{"a", "p"}
+	default:
+		am, pm, ok := strings.Cut(style, "/")
+		if !ok || strings.TrimSpace(am) == "" || strings.TrimSpace(pm) == "" {
+			return markers, fmt.Errorf("invalid am/pm style %q, expected lower, upper, single, or a pair like AM/PM", style)
+		}
+		markers = [2]string{strings.TrimSpace(am), strings.TrimSpace(pm)}
+	}
+	for _, m := range markers {
+		if text.RuneWidthWithoutEscSequences(m) > maxAMPMMarkerWidth {
+			return markers, fmt.Errorf("invalid am/pm style %q, marker %q is wider than %d characters", style, m, maxAMPMMarkerWidth)
+		}
+	}
+	return markers, nil
+}
+
+// padCell right aligns s to the given display width so multi-line cells stay aligned regardless of marker width.
+func padCell(s string, width int) string {
+	if w := text.RuneWidthWithoutEscSequences(s); w < width {
+		return strings.Repeat(" ", width-w) + s
+	}
+	return s
+}
 
-	return zone
+// specifiedTime returns the time requested with --time on the given date, in the local timezone.
+func specifiedTime(date string) time.Time {
+	d, _ := time.Parse(time.DateOnly, date)
+	return time.Date(d.Year(), d.Month(), d.Day(), specifiedHour, specifiedMinute, 0, 0, time.Local)
 }
 
 // getHours returns a slice of time.Time representing the hours of a given date in a specific time zone.
@@ -171,14 +832,14 @@ func getHours(date time.Time, location *time.Location) []time.Time {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
 	// Check if the timezone has a 30-minute offset
-	_, offset := time.Now().In(location).Zone()
+	_, offset := timeNow().In(location).Zone()
 	halfHourOffset := offset%3600 != 0
 
 	// Generate the hours
 	hours := make([]time.Time, 24)
 	for i := range hours {
 		// if the timezone has a 30-minute offset and the current time is past the half hour mark, add 30 minutes to the hour
-		if halfHourOffset && time.Now().UTC().Minute() >= 30 {
+		if halfHourOffset && timeNow().UTC().Minute() >= 30 {
 			hours[i] = start.Add(time.Duration(i)*time.Hour + 30*time.Minute).In(location)
 		} else {
 			hours[i] = start.Add(time.Duration(i) * time.Hour).In(location)
@@ -190,25 +851,78 @@ func getHours(date time.Time, location *time.Location) []time.Time {
 
 // formatHours formats the hours in a given timezone detail.
 // It takes a timezoneDetail struct and a boolean flag indicating whether twelve-hour format is enabled.
+// In twelve-hour format, each cell is the hour above the am/pm marker set with --ampm-style.
+// The cell where the day changes in the timezone shows the name of the new day and is prefixed with the dayChangeMarker,
+// so the day boundary is visible even when it doesn't fall on the first column.
+// If the timezone has working hours configured, the cells outside of working hours are dimmed.
+// If shading is enabled, each cell is shaded by the time of day: with the day/night background colors when color is
+// enabled, otherwise with a line of block characters under the hour.
+// If hour bands are enabled, they take the place of shading: each cell is colored by whether it is in the timezone's
+// working hours, awake, or sleeping, or prefixed with a fill character when color is disabled.
+// If the timezone has a row color, set with row_colors or --row-color, it is used as the text color of each cell, on
+// top of the shading or hour band background.
 // It returns a slice of interfaces representing the formatted hours.
 func formatHours(z timezoneDetail, twelveHourEnabled bool) []interface{} {
 	hours := make([]interface{}, len(z.hours))
 	for i, v := range z.hours {
+		cell := ""
+		day := z.currentTime
+		if i < len(z.hourTimes) {
+			day = z.hourTimes[i]
+		}
 		if v == 0 {
-			hours[i] = fmt.Sprintf("%v", z.currentTime.Format("Mon"))
+			cell = fmt.Sprintf("%v", day.Format("Mon"))
 		} else if twelveHourEnabled {
+			// both lines of the cell are padded to the widest marker so every column has the same width
+			width := max(2, text.RuneWidthWithoutEscSequences(ampmMarkers[0]), text.RuneWidthWithoutEscSequences(ampmMarkers[1]))
 			if v > 12 {
-				hours[i] = fmt.Sprintf("%2v\npm", v-12)
+				cell = padCell(fmt.Sprint(v-12), width) + "\n" + padCell(ampmMarkers[1], width)
 			} else {
-				hours[i] = fmt.Sprintf("%2v\nam", v)
+				cell = padCell(fmt.Sprint(v), width) + "\n" + padCell(ampmMarkers[0], width)
 			}
 		} else {
-			hours[i] = fmt.Sprintf("%2v", v)
+			cell = fmt.Sprintf("%2v", v)
+		}
+		if i > 0 && i < len(z.hourTimes) && day.Day() != z.hourTimes[i-1].Day() {
+			cell = dayChangeMarker + cell
+		}
+		if hourBandsEnabled && !colorEnabled {
+			// the fill goes before the first line, and the other lines are indented to match
+			wh := zoneWorkingHours(z, defaultWorkingHours)
+			cell = bandFillForHour(v, wh[0], wh[1]) + strings.ReplaceAll(cell, "\n", "\n ")
+		} else if shadeEnabled && !colorEnabled {
+			width := 0
+			for _, line := range strings.Split(cell, "\n") {
+				width = max(width, text.RuneWidthWithoutEscSequences(line))
+			}
+			cell += "\n" + strings.Repeat(shadePatternForHour(v), width)
+		}
+		if z.hasWorkHours && i < len(z.hourTimes) {
+			minute := day.Hour()*60 + day.Minute()
+			if minute < z.workStart || minute >= z.workEnd {
+				cell = text.Colors{text.Faint}.Sprint(cell)
+			}
+		}
+		var style text.Colors
+		if hourBandsEnabled && colorEnabled {
+			wh := zoneWorkingHours(z, defaultWorkingHours)
+			style = cellStyle(v, wh[0], wh[1], bandColors)
+		} else if shadeEnabled && colorEnabled {
+			style = cellColorForHour(v, shadeColors)
 		}
+		if style = rowStyle(z, style); len(style) > 0 {
+			cell = style.Sprint(cell)
+		}
+		hours[i] = cell
 	}
 	return hours
 }
 
+// epochForHour returns the unix timestamp at the start of the hour containing t.
+func epochForHour(t time.Time) int64 {
+	return t.Truncate(time.Hour).Unix()
+}
+
 // formatOffset formats the offset of a timezoneDetail struct into a string representation.
 // It takes a timezoneDetail struct as input and returns the formatted offset as a string with a +/- sign.
 func formatOffset(z timezoneDetail) string {
@@ -224,32 +938,88 @@ func formatOffset(z timezoneDetail) string {
 	return offset
 }
 
+// formatRelativeOffset formats the difference between the offset of a timezoneDetail struct and a base offset, both in
+// minutes east of UTC. Like formatOffset it always includes a +/- sign, but partial hours are shown as minutes, i.e. +5,
+// -9:30, or +5:45, since differences between zones aren't limited to half hours.
+func formatRelativeOffset(z timezoneDetail, baseMinutes int) string {
+	diff := z.offsetMinutes - baseMinutes
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+		diff = -diff
+	}
+	if diff%60 != 0 {
+		return fmt.Sprintf("%s%d:%02d", sign, diff/60, diff%60)
+	}
+	return fmt.Sprintf("%s%d", sign, diff/60)
+}
+
+// referenceOffsetMinutes returns the offset, in minutes east of UTC, of the reference timezone for relative offsets at
+// time t. The reference timezone is local unless one was set with --relative-to.
+func referenceOffsetMinutes(t time.Time) (int, error) {
+	loc, err := loadLocation(relativeTo)
+	if err != nil {
+		return 0, err
+	}
+	_, offset := t.In(loc).Zone()
+	return offset / 60, nil
+}
+
+// clockEmoji returns the Unicode clock face emoji nearest to the given time, rounded to the nearest half hour.
+// i.e. 3:10 returns 🕒 and 3:20 returns 🕞.
+func clockEmoji(t time.Time) string {
+	halfHours := (t.Hour()*60 + t.Minute() + 15) / 30 % 48
+	hour := halfHours / 2 % 12
+	if hour == 0 {
+		hour = 12
+	}
+	// 🕐 through 🕛 are the on the hour faces, 🕜 through 🕧 are the half past faces
+	if halfHours%2 == 0 {
+		return string(rune(0x1F550 + hour - 1))
+	}
+	return string(rune(0x1F55C + hour - 1))
+}
+
 // formatRowLabel formats the row label for a timezone detail.
 // It takes a timezoneDetail struct, a date string, and an offset string as input.
 // If the date is not the current date, it returns the formatted row label with the timezone name, abbreviation, and offset.
-// If the date is the current date, it returns the formatted row label with the timezone name, abbreviation, offset, and current time.
-func formatRowLabel(z timezoneDetail, date, offset string) string {
+// If the date is the current date, or a time was specified with --time, it returns the formatted row label with the timezone name,
+// abbreviation, offset, and current time.
+// If an icon is given, the label is prefixed with it.
+// If emoji are enabled, the label is prefixed with the clock emoji nearest to the current time in the timezone.
+// If numbering is enabled, the label is prefixed with the position of the timezone in the list.
+func formatRowLabel(z timezoneDetail, date, offset, icon string) string {
 	rowLabel := ""
-	if date != time.Now().Format(time.DateOnly) {
+	if date != timeNow().Format(time.DateOnly) && timeOfDay == "" {
 		rowLabel = fmt.Sprintf("%s [%s,%s]", z.name, z.abbreviation, offset)
 	} else {
 		rowLabel = fmt.Sprintf("%s [%s,%s]\n%s", z.name, z.abbreviation, offset, z.currentTime.Format("Monday, Jan 2 3:04PM"))
 	}
+	if icon != "" {
+		rowLabel = fmt.Sprintf("%s %s", icon, rowLabel)
+	}
+	if emojiEnabled {
+		rowLabel = fmt.Sprintf("%s %s", clockEmoji(z.currentTime), rowLabel)
+	}
+	if numberedEnabled {
+		rowLabel = fmt.Sprintf("%d. %s", z.index, rowLabel)
+	}
 	return rowLabel
 }
 
-// printTimeTable prints the time table for the given zones.
+// newTimeTable builds the time table for the given zones, without rendering it.
 // It takes a slice of timezoneDetails and a boolean flag indicating whether color is enabled.
-// The function uses the table package to create a table and display the time information.
+// The function uses the table package to create a table holding the time information.
 // If colorEnabled is true, the table is styled with colored text, otherwise it is styled with rounded borders.
 // If the requested date is not today, a table caption is added with the date in the format "Monday, January 2, 2006".
 // If the requested date is today, the current local time is displayed in the table title.
+// If a time was specified with --time, it is displayed in the title and its UTC hour is highlighted.
+// If the relative column is enabled, a column with the offset relative to the reference timezone follows the row label.
+// If the format is unix, a final "UTC epoch" row shows the unix timestamp at the start of each column's hour.
 // The function iterates over the zones and formats the hours, offset, and row label for each zone.
 // The formatted data is then appended to the table row and the row is added to the table.
-// Finally, the table is rendered and displayed on the console.
-func printTimeTable(zones timezoneDetails, colorEnabled bool) {
+func newTimeTable(zones timezoneDetails, colorEnabled bool) table.Writer {
 	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
 	if colorEnabled {
 		t.SetStyle(table.StyleColoredBlackOnBlueWhite)
 		t.Style().Title.Colors = text.Colors{text.BgHiBlue, text.FgHiWhite}
@@ -264,26 +1034,452 @@ func printTimeTable(zones timezoneDetails, colorEnabled bool) {
 	}
 	t.Style().Title.Align = text.AlignCenter
 
-	if date != time.Now().Format(time.DateOnly) {
+	// the first column holds the timezone and, if enabled, the second holds the relative offset. Column numbers start at 1.
+	firstHourColumn := 2
+	if relativeColumnEnabled {
+		firstHourColumn = 3
+	}
+
+	var title string
+	if timeOfDay != "" {
+		// time requested, identify the table column holding the UTC equivalent of the requested time
+		st := specifiedTime(date)
+		t.SetIndexColumn(st.UTC().Hour() + firstHourColumn)
+		title = "Showing Time For: " + st.Format("Monday, January 2, 2006 3:04 PM MST")
+	} else if date != timeNow().Format(time.DateOnly) {
 		// add table caption if requested date is not today
 		d, _ := time.Parse(time.DateOnly, date)
-		t.SetTitle("Showing Time For: %s", d.Format("Monday, January 2, 2006 MST"))
+		title = "Showing Time For: " + d.Format("Monday, January 2, 2006 MST")
+		if highlightNowEnabled {
+			// the current hour on another date, i.e. to compare it either side of a DST change
+			t.SetIndexColumn(timeNow().UTC().Hour() + firstHourColumn)
+		}
 	} else {
 		// date requested == today, identify the table column holding the current hour
-		t.SetIndexColumn(time.Now().UTC().Hour() + 2) // +2 because first col=timezone and hours count from 0
-		t.SetTitle("Current Local Time: %s", time.Now().Format("Monday, January 2, 2006 3:04:05 PM MST"))
+		t.SetIndexColumn(timeNow().UTC().Hour() + firstHourColumn)
+		title = "Current Local Time: " + timeNow().Format("Monday, January 2, 2006 3:04:05 PM MST")
+	}
+	// echo a date typed like 15.06.2024 as YYYY-MM-DD, so it's clear which day and month it was read as
+	if localeDate {
+		title += " (" + date + ")"
 	}
+	// a column is a whole hour, so show the exact time of highlights that start part way through one
+	if len(highlightInstants) > 0 {
+		title += " (highlight = " + strings.Join(highlightInstants, ", ") + ")"
+	}
+	t.SetTitle(title)
 
-	for _, z := range zones {
-		hours := formatHours(z, twelveHourEnabled)
-		offset := formatOffset(z)
-		rowLabel := formatRowLabel(z, date, offset)
+	// go-pretty only supports a single index column, so columns highlighted with --highlight are colored instead
+	var columnConfigs []table.ColumnConfig
+	for _, c := range highlightColumns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Number: c + firstHourColumn, Colors: highlightColors(colorEnabled)})
+	}
+	for _, c := range highlightTrailingColumns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Number: c + firstHourColumn, Colors: highlightTrailingColors(colorEnabled)})
+	}
+	t.SetColumnConfigs(columnConfigs)
+
+	// with --group-regions, each run of timezones in the same region follows a row with the region's name
+	groups := []regionGroup{{zones: zones}}
+	if groupRegionsEnabled {
+		groups = groupZonesByRegion(zones)
+	}
+	for _, g := range groups {
+		if g.label != "" && len(zones) > 0 {
+			appendRegionRow(t, g.label, firstHourColumn-1+len(zones[0].hours))
+		}
+		for _, z := range g.zones {
+			hours := formatHours(z, twelveHourEnabled)
+			offset := formatOffset(z)
+			icon := ""
+			if iconsEnabled {
+				icon = z.icon
+			}
+			rowLabel := formatRowLabel(z, date, offset, icon)
+			// each line is colored on its own, since the table resets colors at the end of a line
+			if style := rowStyle(z, nil); len(style) > 0 {
+				lines := strings.Split(rowLabel, "\n")
+				for i, line := range lines {
+					lines[i] = style.Sprint(line)
+				}
+				rowLabel = strings.Join(lines, "\n")
+			}
+
+			row := []interface{}{rowLabel}
+			if relativeColumnEnabled {
+				row = append(row, formatRelativeOffset(z, z.relativeBaseMinutes))
+			}
+			row = append(row, hours...)
+			t.AppendRow(row)
+		}
+	}
 
-		row := append([]interface{}{rowLabel}, hours...)
+	if format == "unix" && len(zones) > 0 {
+		row := []interface{}{"UTC epoch"}
+		if relativeColumnEnabled {
+			row = append(row, "")
+		}
+		for _, h := range zones[0].hourTimes {
+			row = append(row, epochForHour(h))
+		}
 		t.AppendRow(row)
 	}
 
-	t.Render()
+	return t
+}
+
+// sprintTimeTable renders the time table for the given zones and returns it, without a trailing newline. See
+// newTimeTable.
+func sprintTimeTable(zones timezoneDetails, colorEnabled bool) string {
+	return newTimeTable(zones, colorEnabled).Render()
+}
+
+// printTimeTable renders the time table for the given zones to w. See newTimeTable.
+func printTimeTable(w io.Writer, zones timezoneDetails, colorEnabled bool) {
+	fmt.Fprintln(w, sprintTimeTable(zones, colorEnabled))
+}
+
+// SprintTimeTable renders the time table for the given zones on the date and returns it, without a trailing newline,
+// for use outside of the commands. highlightHour is the UTC hour of the column to highlight, or -1 for none. The
+// date, highlights, and 12-hour format the commands set from their flags are restored afterwards.
+func SprintTimeTable(zones timezoneDetails, colorEnabled bool, highlightHour int, twelveHour bool, date string) string {
+	defer saveTableState()()
+
+	var columns []int
+	if highlightHour >= 0 {
+		columns = []int{highlightHour % 24}
+	}
+	setTableState(date, twelveHour, columns, nil, nil)
+	return sprintTimeTable(zones, colorEnabled)
+}
+
+// SRenderTimeTable looks up the timezones on the date and renders their time table to a string, like timeBuddy does
+// to stdout. highlight accepts the same values as --highlight, or is empty for none. The logger is used for the
+// timezone lookups, and cmd's context stops them early, i.e. on Ctrl+C.
+func SRenderTimeTable(cmd *cobra.Command, log *zerolog.Logger, timezones []string, date string, colorEnabled, twelveHour bool, highlight string) (string, error) {
+	ctx := context.Background()
+	if cmd != nil && cmd.Context() != nil {
+		ctx = cmd.Context()
+	}
+	zones, err := processTimezones(ctx, timezones, date, log)
+	if err != nil {
+		return "", err
+	}
+
+	defer saveTableState()()
+
+	var columns, trailing []int
+	var instants []string
+	if highlight != "" {
+		if columns, trailing, instants, err = parseHighlightFlag([]string{highlight}, zones, date, 0); err != nil {
+			return "", err
+		}
+	}
+	setTableState(date, twelveHour, columns, trailing, instants)
+
+	var buf bytes.Buffer
+	printTimeTable(&buf, zones, colorEnabled)
+	return buf.String(), nil
+}
+
+// saveTableState returns a function that restores the date, 12-hour format, and highlights the time table is rendered
+// with to their current values.
+func saveTableState() func() {
+	d, twelveHour := date, twelveHourEnabled
+	columns, trailing, instants := highlightColumns, highlightTrailingColumns, highlightInstants
+	return func() {
+		setTableState(d, twelveHour, columns, trailing, instants)
+	}
+}
+
+// setTableState sets the date, 12-hour format, and highlights the time table is rendered with.
+func setTableState(d string, twelveHour bool, columns, trailing []int, instants []string) {
+	date, twelveHourEnabled = d, twelveHour
+	highlightColumns, highlightTrailingColumns, highlightInstants = columns, trailing, instants
+}
+
+// openOutputFile opens the file given with --output, truncating it unless appendOutput is true.
+func openOutputFile(path string, appendOutput bool) (*os.File, error) {
+	if appendOutput {
+		return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	}
+	return os.Create(path)
+}
+
+// zoneDisplayName returns a friendly name for a timezone, i.e. America/New_York becomes New York.
+func zoneDisplayName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// renderSlack renders the time in each zone at refTime on a single line suitable for pasting into Slack, i.e.
+// 🇺🇸 New York (EDT, UTC-4): Mon 14:30 • 🇬🇧 London (BST, UTC+1): Mon 19:30
+// Each zone is prefixed with the flag of its country, looked up by abbreviation, unless --no-emoji was provided.
+func renderSlack(zones timezoneDetails, refTime time.Time) string {
+	layout := "Mon 15:04"
+	if twelveHourEnabled {
+		layout = "Mon 3:04PM"
+	}
+	parts := make([]string, 0, len(zones))
+	for _, z := range zones {
+		loc, err := loadLocation(z.name)
+		if err != nil {
+			l.Fatal().Str("timezone", z.name).Err(err).Send()
+		}
+		part := fmt.Sprintf("%s (%s, UTC%s): %s", zoneDisplayName(z.name), z.abbreviation, formatRelativeOffset(z, 0), refTime.In(loc).Format(layout))
+		if flag, ok := abbreviationToFlag[z.abbreviation]; ok && !noEmoji {
+			part = flag + " " + part
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// printFormat renders the zones using a user supplied Go text/template instead of a table.
+// The template is executed against the timezoneDetails slice, so it will usually range over it.
+// Parse and execution errors are returned as-is since they include the position of the problem in the template.
+// An error is also returned if the template produces no output, so scripts notice a mistake.
+func printFormat(w io.Writer, zones timezoneDetails, format string) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, zones); err != nil {
+		return err
+	}
+
+	out := sb.String()
+	if strings.TrimSpace(out) == "" {
+		return fmt.Errorf("format template produced no output")
+	}
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = fmt.Fprint(w, out)
+	return err
+}
+
+// selectRows returns only the zones at the 1-based positions requested, in the order requested.
+// It returns an error if any of the positions are outside the list of zones.
+func selectRows(zones timezoneDetails, rows []int) (timezoneDetails, error) {
+	var selected timezoneDetails
+	for _, r := range rows {
+		if r < 1 || r > len(zones) {
+			return nil, fmt.Errorf("row %d is out of range, expected a value between 1 and %d", r, len(zones))
+		}
+		selected = append(selected, zones[r-1])
+	}
+	return selected, nil
+}
+
+// maxRangeDays is the most days --from and --to can span, so a typo in a year doesn't print hundreds of tables
+const maxRangeDays = 14
+
+// dateRange returns each day from one date to another, including both, as YYYY-MM-DD. Both dates accept the same values
+// as --date.
+func dateRange(from, to string) ([]string, error) {
+	var days [2]time.Time
+	for i, s := range []string{from, to} {
+		resolved, err := resolveRelativeDate(s)
+		if err != nil {
+			return nil, err
+		}
+		if days[i], err = time.Parse(time.DateOnly, resolved); err != nil {
+			return nil, err
+		}
+	}
+	if days[1].Before(days[0]) {
+		return nil, fmt.Errorf("--to %s is before --from %s", days[1].Format(time.DateOnly), days[0].Format(time.DateOnly))
+	}
+	var dates []string
+	for d := days[0]; !d.After(days[1]); d = d.AddDate(0, 0, 1) {
+		if len(dates) == maxRangeDays {
+			return nil, fmt.Errorf("range can't be longer than %d days", maxRangeDays)
+		}
+		dates = append(dates, d.Format(time.DateOnly))
+	}
+	return dates, nil
+}
+
+// epochDatePattern matches a unix timestamp given with --date, in seconds or milliseconds. Shorter numbers aren't
+// treated as timestamps, since they're more likely a mistyped date.
+var epochDatePattern = regexp.MustCompile(`^\d{9,}$`)
+
+// dateTimeLayouts are the layouts of a date and time accepted by --date. Those without an offset are in local time.
+var dateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02 15:04Z07:00", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// parseDateAndTime parses a date and time like 2024-11-05T15:00+11:00 or "2024-11-05 15:00", and reports whether it
+// matched one of dateTimeLayouts.
+func parseDateAndTime(s string) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// timeNow returns the current time, or the time given with --now
+var timeNow = time.Now
+
+// parseNowFlag parses --now, a date and time accepted by parseDateAndTime like 2024-06-15T14:00:00Z, or a time of day
+// accepted by parseTimeString like 14:00, which is today in local time.
+func parseNowFlag(s string) (time.Time, error) {
+	if t, ok := parseDateAndTime(s); ok {
+		return t.Local(), nil
+	}
+	hour, minute, err := parseTimeString(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date and time like 2024-06-15T14:00:00Z, or a time of day like 14:00: %w", err)
+	}
+	n := time.Now()
+	return time.Date(n.Year(), n.Month(), n.Day(), hour, minute, 0, 0, time.Local), nil
+}
+
+// date-format config key values, the order of the month and day in a date with slashes like 03/04/2024
+const (
+	dateFormatMDY = "mdy"
+	dateFormatDMY = "dmy"
+)
+
+var (
+	// dottedDatePattern matches a day first date with dots, as is common in Europe, i.e. 15.06.2024
+	dottedDatePattern = regexp.MustCompile(`^\d{1,2}\.\d{1,2}\.\d{4}$`)
+	// slashDatePattern matches a date with slashes, whose month and day may be in either order, i.e. 06/15/2024
+	slashDatePattern = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/\d{4}$`)
+)
+
+// dateFormat returns the date-format config key. The root command resolves --date in Args, before the config file is
+// read into v, so the file is read directly then.
+func dateFormat() string {
+	if v.IsSet("date-format") {
+		return strings.ToLower(v.GetString("date-format"))
+	}
+	if fv, err := readConfigFile(); err == nil {
+		return strings.ToLower(fv.GetString("date-format"))
+	}
+	return ""
+}
+
+// parseLocaleDate parses a date in one of the layouts people commonly type other than YYYY-MM-DD: 15.06.2024,
+// 06/15/2024, or Jun 15 2024. ok is false if s isn't in any of them. A date with slashes is month first, or day first if
+// the first number can't be a month. One that could be either, like 03/04/2024, is an error unless the date-format config
+// key is mdy or dmy.
+func parseLocaleDate(s string) (t time.Time, ok bool, err error) {
+	switch {
+	case dottedDatePattern.MatchString(s):
+		t, err = time.Parse("2.1.2006", s)
+		return t, true, err
+	case slashDatePattern.MatchString(s):
+		m := slashDatePattern.FindStringSubmatch(s)
+		first, _ := strconv.Atoi(m[1])
+		second, _ := strconv.Atoi(m[2])
+		format := dateFormat()
+		switch format {
+		case "", dateFormatMDY, dateFormatDMY:
+		default:
+			return time.Time{}, true, fmt.Errorf("invalid date-format %q in the config file, expected %s or %s", format, dateFormatMDY, dateFormatDMY)
+		}
+		if format == "" && first != second && first <= 12 && second <= 12 {
+			return time.Time{}, true, fmt.Errorf("ambiguous date %q, could be month/day/year or day/month/year; use YYYY-MM-DD, or set date-format to %s or %s in the config file", s, dateFormatMDY, dateFormatDMY)
+		}
+		layout := "1/2/2006"
+		if first > 12 || (format == dateFormatDMY && second <= 12) {
+			layout = "2/1/2006"
+		}
+		t, err = time.Parse(layout, s)
+		return t, true, err
+	}
+	if t, err := time.Parse("Jan 2 2006", s); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// resolveRelativeDate converts a relative date into a YYYY-MM-DD string.
+// It accepts "today", "tomorrow", "yesterday", a weekday like "monday" or "mon", which is the next one after today, or a
+// number of days or weeks relative to today, like "+7d", "-3d", or "+2w".
+// Days are added to the calendar date, so the result is valid even when the offset crosses a DST transition.
+// A date in a layout accepted by parseLocaleDate, like 15.06.2024, is converted to YYYY-MM-DD.
+// Any other value is returned unchanged so it can be validated as a YYYY-MM-DD date.
+func resolveRelativeDate(s string) (string, error) {
+	now := timeNow()
+	lower := strings.ToLower(s)
+	switch lower {
+	case "today":
+		return now.Format(time.DateOnly), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format(time.DateOnly), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format(time.DateOnly), nil
+	}
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if lower == name || lower == name[:3] {
+			// the next one, so the weekday of today is a week away
+			days := (int(wd)-int(now.Weekday())+6)%7 + 1
+			return now.AddDate(0, 0, days).Format(time.DateOnly), nil
+		}
+	}
+
+	if (strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-")) && (strings.HasSuffix(lower, "d") || strings.HasSuffix(lower, "w")) {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err != nil || strings.ContainsAny(s[1:len(s)-1], "+-") {
+			return "", fmt.Errorf("invalid relative date %q, expected a format like +7d, -3d, or +2w", s)
+		}
+		if s[0] == '-' {
+			n = -n
+		}
+		if strings.HasSuffix(lower, "w") {
+			n *= 7
+		}
+		return now.AddDate(0, 0, n).Format(time.DateOnly), nil
+	}
+
+	if t, ok, err := parseLocaleDate(s); ok {
+		if err != nil {
+			return "", err
+		}
+		return t.Format(time.DateOnly), nil
+	}
+
+	return s, nil
+}
+
+// defaultRecentlyUsedLimit is how many recently used timezones are remembered when recently_used_limit isn't set
+const defaultRecentlyUsedLimit = 10
+
+// updateRecentlyUsed returns the recently used timezones with the given timezones moved to the front, deduplicated and
+// trimmed to the limit.
+func updateRecentlyUsed(recent, used []string, limit int) []string {
+	updated := deduplicateSlice(append(append([]string{}, used...), recent...))
+	if len(updated) > limit {
+		updated = updated[:limit]
+	}
+	return updated
+}
+
+// includeLocalTimezone returns the timezones with the local timezone prepended, unless it is already in the list.
+func includeLocalTimezone(tzs []string) []string {
+	ltz, err := time.LoadLocation("Local")
+	if err != nil {
+		// the local timezone may not resolve in unusual environments, i.e. a container without tzdata
+		if ignoreErrors {
+			return tzs
+		}
+		l.Fatal().Err(err).Send()
+	}
+	for _, tz := range tzs {
+		if tz == ltz.String() {
+			return tzs
+		}
+	}
+	return append([]string{ltz.String()}, tzs...)
 }
 
 // deduplicateSlice removes duplicate elements from a string slice.
@@ -312,18 +1508,32 @@ func deduplicateSlice(s []string) []string {
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:     "timeBuddy",
-	Version: "v1.1.9",
+	Version: buildinfo.Version,
 	Short:   "CLI version of World Time Buddy",
 	Long: `timeBuddy is a Command Line Interface (CLI) tool designed to display the current time across multiple time zones. This
 tool is particularly useful for scheduling meetings with participants in various time zones. By default, timeBuddy
-includes your local time zone in its output. You can exclude your local time zone using the --exclude-local flag.
+includes your local time zone in its output. You can exclude your local time zone using the --no-local flag, no-local:
+true in the configuration file, or TIMEBUDDY_NO_LOCAL=1.
 
 timeBuddy saves your most recent time zone selections in a configuration file. This feature ensures that you need to
 specify your preferred time zones only once. The order in which you specify the time zones is retained and reflected in
-the table output. You can find the configuration file at the following locations:
+the table output. On the first run, you're asked which time zones to show, unless TIMEBUDDY_SKIP_ONBOARDING is set.
+You can find the configuration file at the following locations:
+
+  - Linux: $XDG_CONFIG_HOME/timebuddy/config.yaml, or $HOME/.config/timebuddy/config.yaml if it isn't set
+  - Mac: $HOME/Library/Application Support/timebuddy/config.yaml
+  - Windows: %APPDATA%\timebuddy\config.yaml
+
+A config file in the location used by older versions, $HOME/.config/.timeBuddy.yaml or %APPDATA%\.timeBuddy.yaml, is
+moved there the next time timeBuddy runs.
 
-  - Linux/Mac: $HOME/.config/.timeBuddy.yaml
-  - Windows: %APPDATA%\.timeBuddy.yaml
+A .timeBuddy.yaml in the working directory, or a directory above it below $HOME, is a project config file. Its
+timezone, color, twelve-hour, and working_hours values take precedence over your config file, but aren't saved to it.
+Use --no-project-config to ignore it.
+
+Time zones can also be set with the TIMEBUDDY_TIMEZONES environment variable, a list separated by commas or colons,
+i.e. TIMEBUDDY_TIMEZONES="UTC,Europe/Berlin". It takes precedence over the configuration file, and --timezone takes
+precedence over it.
 
 Examples:
 
@@ -332,43 +1542,239 @@ Examples:
 
   # Display the current time for a selection of time zones:
   $ timeBuddy --timezone America/New_York --timezone Europe/Vilnius --timezone Australia/Sydney
+  $ timeBuddy --timezone America/New_York,Europe/Vilnius,Australia/Sydney
+
+  # Time zones can also be given by city, which are saved as the time zone they are in:
+  $ timeBuddy --timezone Paris --timezone "São Paulo"
 
   # Display Time for a specific date(useful for checking times during Daylight Saving Time changes):
   $ timeBuddy --date 2023-11-05 --timezone America/New_York --timezone Europe/Vilnius --timezone Australia/Sydney
 
+  # Display time for a specific date and time of day in your local time zone:
+  $ timeBuddy --date 2025-06-15 --time 14:30
+  $ timeBuddy --date tomorrow --time 3pm
+
+  # Highlight two candidate meeting slots, 3pm in a zone at UTC+11 and 9am in a zone at UTC-5:
+  $ timeBuddy --highlight 15+11 --highlight 9-5
+
+  # Highlight 3pm in your local time zone, then a two hour meeting from 3pm to 5pm local time:
+  $ timeBuddy --highlight 15
+  $ timeBuddy --highlight 15-17L
+
+  # Highlight 3pm on Nov 5th at UTC+11, on the table for that day:
+  $ timeBuddy --date 2024-11-05T15:00+11:00
+
+  # Show a table for each day of a weekend with a DST change:
+  $ timeBuddy --from 2025-03-28 --to 2025-03-31
+
+  # Highlight the current hour on the day DST ends, to compare it with today:
+  $ timeBuddy --date 2025-10-26 --highlight-now
+
+  # Highlight 4pm at UTC+1 on every run, then stop:
+  $ timeBuddy --highlight 16+1 --save-highlight
+  $ timeBuddy --highlight ""
+
+  # Highlight a two hour meeting from 11pm to 1am at UTC+0:
+  $ timeBuddy --highlight 23-1+0
+
+  # Highlight a 90 minute meeting starting at 3pm at UTC+11, with its start in a brighter color:
+  $ timeBuddy --highlight 15+11 --duration 90
+
+  # Highlight 3pm in Sydney, whatever its UTC offset is on the date:
+  $ timeBuddy --date 2025-07-01 --highlight 15@Australia/Sydney
+
+  # Display the time of a unix timestamp, i.e. from a server log:
+  $ timeBuddy --unix 1718467800
+
+  # Add a row with the unix timestamp at the start of each hour:
+  $ timeBuddy --format unix
+
+  # Display time for tomorrow, or for a week from today:
+  $ timeBuddy --date tomorrow
+  $ timeBuddy --date +7d
+
+  # Group the time zones by region, with the region's name above each group:
+  $ timeBuddy --group-regions --timezone America/New_York --timezone Europe/London --timezone Asia/Tokyo
+
+  # Show the table as if it were 2pm, i.e. for a screenshot:
+  $ timeBuddy --now 14:00
+  $ timeBuddy --now 2024-06-15T14:00:00Z
+
+  # Display time for a date typed day first, or month first. Set date-format to dmy or mdy in the config file to read
+  # dates like 03/04/2024, which could be either:
+  $ timeBuddy --date 15.06.2024
+  $ timeBuddy --date 06/15/2024
+
+  # Read the time zones from a file shared by your team, one per line:
+  $ timeBuddy --timezone-file zones.txt
+  $ cat zones.txt | timeBuddy --timezone Local --timezone-file -
+
+  # Leave time zones saved in the config file out of the table, for this run only:
+  $ timeBuddy --without Europe/London --without Asia/Tokyo
+
+  # Number the rows, then show only the 2nd and 4th rows:
+  $ timeBuddy --numbered
+  $ timeBuddy --numbered --only 2,4
+
   # Exclude your local time zone from the output:
-   $ timeBuddy --exclude-local --timezone --timezone Europe/London --timezone Asia/Tokyo
+   $ timeBuddy --no-local --timezone --timezone Europe/London --timezone Asia/Tokyo
 
   # Enable colorized table output:
    $ timeBuddy --color
 
+  # Shade each hour by whether it is night, dawn or evening, or day in that time zone:
+   $ timeBuddy --color --shade --night-color 17 --day-color '#2e7d32'
+
+  # Color each hour by whether it is in working hours, awake, or sleeping in that time zone:
+   $ timeBuddy --color --hour-bands --working-hours America/New_York=08:00-16:00
+
+  # Color the text of a time zone's row, on top of any shading. Rows can also be colored with a row_colors map in the
+  # config file, i.e. row_colors: {America/New_York: "#FF0000"}:
+   $ timeBuddy --color --shade --row-color America/New_York=#FF0000 --row-color Europe/London=33
+
+  # Print a one-liner, i.e. for a tmux status bar, instead of a table. The template is a Go text/template executed
+  # against the list of time zones, each of which has .Name, .Abbrev, .Offset, and .Time fields:
+   $ timeBuddy --format '{{range $i, $z := .}}{{if $i}} | {{end}}{{$z.Abbrev}} {{$z.Time.Format "15:04"}}{{end}}'
+
+  # Print a single line suitable for pasting into Slack:
+   $ timeBuddy --format slack
+
+  # Print each time zone on its own line with its UTC offset:
+   $ timeBuddy --format '{{range .}}{{.Name}} (UTC{{.Offset}}) {{.Time.Format "Mon 3:04PM"}}{{"\n"}}{{end}}'
+
 Learn More:
   To submit feature requests, bugs, or to check for new versions, visit https://github.com/JakeTRogers/timeBuddy`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		// if the --date flag was provided, validate it
+		// --now is handled first, since relative dates, --time now, and the default date are relative to it
+		if cmd.Flags().Changed("now") {
+			t, err := parseNowFlag(nowOverride)
+			if err != nil {
+				l.Fatal().Str("now", nowOverride).Err(err).Send()
+			}
+			timeNow = func() time.Time { return t }
+			if !cmd.Flags().Changed("date") {
+				date = t.Format(time.DateOnly)
+			}
+		}
+
+		// if the --date flag was provided, resolve relative dates and validate it
 		if cmd.Flags().Changed("date") {
-			_, err := time.Parse(time.DateOnly, date)
+			// a unix timestamp is handled like --unix, so the title shows the moment it resolved to
+			if epochDatePattern.MatchString(date) {
+				if cmd.Flags().Changed("time") {
+					l.Fatal().Str("date", date).Err(fmt.Errorf("--time can't be used with a unix timestamp in --date")).Send()
+				}
+				t, _, err := parseEpoch(date)
+				if err != nil {
+					l.Fatal().Str("date", date).Err(err).Send()
+				}
+				t = t.Local()
+				date, timeOfDay = t.Format(time.DateOnly), t.Format("15:04")
+				specifiedHour, specifiedMinute = t.Hour(), t.Minute()
+			}
+			// a date and time highlights the moment, in the local date's table
+			if t, ok := parseDateAndTime(date); ok {
+				for _, name := range []string{"highlight", "highlight-now", "time"} {
+					if cmd.Flags().Changed(name) {
+						l.Fatal().Str("date", date).Err(fmt.Errorf("--%s can't be used with a date and time in --date", name)).Send()
+					}
+				}
+				t = t.Local()
+				date, dateHighlight = t.Format(time.DateOnly), t.Format("15:04")+"L"
+			}
+			if _, ok, _ := parseLocaleDate(date); ok {
+				localeDate = true
+			}
+			resolved, err := resolveRelativeDate(date)
+			if err != nil {
+				l.Fatal().Str("date", date).Err(err).Send()
+			}
+			date = resolved
+			_, err = time.Parse(time.DateOnly, date)
 			if err != nil {
 				l.Fatal().Str("date", date).Err(err).Send()
 			}
 		}
 
-		// if the --exclude-local flag was NOT provided explicitly, add the local timezone to the timezones slice
-		if !cmd.Flags().Changed("exclude-local") {
-			ltz, err := time.LoadLocation("Local")
+		// if --from and --to were provided, show a table for each day between them, starting with the first
+		if cmd.Flags().Changed("from") || cmd.Flags().Changed("to") {
+			if !cmd.Flags().Changed("from") || !cmd.Flags().Changed("to") {
+				l.Fatal().Err(fmt.Errorf("--from and --to must be used together")).Send()
+			}
+			for _, name := range []string{"date", "unix"} {
+				if cmd.Flags().Changed(name) {
+					l.Fatal().Err(fmt.Errorf("--%s can't be used with --from and --to", name)).Send()
+				}
+			}
+			dates, err := dateRange(rangeFrom, rangeTo)
+			if err != nil {
+				l.Fatal().Str("from", rangeFrom).Str("to", rangeTo).Err(err).Send()
+			}
+			rangeDates, date = dates, dates[0]
+		}
+
+		// if the --time flag was provided, validate it and store the hour and minute
+		if cmd.Flags().Changed("time") {
+			hour, minute, err := parseTimeString(timeOfDay)
+			if err != nil {
+				l.Fatal().Str("time", timeOfDay).Err(err).Send()
+			}
+			specifiedHour, specifiedMinute = hour, minute
+		}
+
+		// if the --unix flag was provided, use the timestamp's local date and time as if --date and --time were provided
+		if cmd.Flags().Changed("unix") {
+			t, _, err := parseEpoch(unixTime)
 			if err != nil {
+				l.Fatal().Str("unix", unixTime).Err(err).Send()
+			}
+			t = t.Local()
+			date = t.Format(time.DateOnly)
+			timeOfDay = t.Format("15:04")
+			specifiedHour, specifiedMinute = t.Hour(), t.Minute()
+		}
+
+		// if the --relative-to flag was provided, validate it
+		if cmd.Flags().Changed("relative-to") {
+			if _, err := loadLocation(relativeTo); err != nil {
+				l.Fatal().Str("relative-to", relativeTo).Err(err).Send()
+			}
+		}
+
+		// --flags is another name for --icon. It sets --icon, so the config file doesn't override it.
+		if f := cmd.Flags().Lookup("flags"); f.Changed {
+			if err := cmd.Flags().Set("icon", f.Value.String()); err != nil {
 				l.Fatal().Err(err).Send()
 			}
-			found := false
-			for _, tz := range timezones {
-				if tz == ltz.String() {
-					found = true
-					break
+		}
+
+		// remember whether timezones were given on the command line, before the config file fills in the flag
+		timezoneFlagChanged = cmd.Flags().Changed("timezone")
+		// a single --timezone may hold a comma separated list, i.e. -z UTC,Asia/Tokyo. Timezone names never contain commas.
+		if timezoneFlagChanged {
+			timezones = splitTimezoneList(timezones)
+		}
+		highlightFlagChanged = cmd.Flags().Changed("highlight")
+		// a preset's color and 12-hour settings apply unless these are given on the command line
+		colorFlagChanged = cmd.Flags().Changed("color")
+		twelveHourFlagChanged = cmd.Flags().Changed("twelve-hour")
+
+		if presetName != "" && profile != "" {
+			l.Fatal().Err(fmt.Errorf("--preset can't be used with --profile")).Send()
+		}
+
+		if appendOutput && outputFile == "" {
+			l.Fatal().Err(fmt.Errorf("--append can only be used with --output")).Send()
+		}
+
+		// checked here rather than with MarkFlagsMutuallyExclusive, since a highlight saved in the config file fills in
+		// --highlight and shouldn't conflict
+		if highlightNowEnabled {
+			for _, name := range []string{"highlight", "time", "unix"} {
+				if cmd.Flags().Changed(name) {
+					l.Fatal().Err(fmt.Errorf("--highlight-now can't be used with --%s", name)).Send()
 				}
 			}
-			if !found {
-				timezones = append([]string{ltz.String()}, timezones...)
-			}
 		}
 
 		// deduplicate timezones in case the user specified the same timezone multiple times
@@ -378,28 +1784,314 @@ Learn More:
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// bind cobra and viper
-		return initializeConfig(cmd)
+		return initializeConfig(cmd, v, l)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		for k, v := range v.AllSettings() {
 			l.Debug().Str(k, fmt.Sprintf("%v", v)).Msg("viper:")
 		}
 
-		// write preferences to config file
-		v.Set("color", colorEnabled)
-		v.Set("timezone", timezones)
-		v.Set("twelve-hour", twelveHourEnabled)
-		if err := v.WriteConfig(); err != nil {
-			l.Error().Str("viper", err.Error()).Send()
+		// --no-icon turns off an icon setting from the config file
+		if noIcon {
+			iconsEnabled = false
+		}
+
+		// a preset's settings are used for this run only, so they aren't saved to the config file
+		var preset timezonePreset
+		if presetName != "" {
+			p, ok := presetFromConfig(v, presetName)
+			if !ok {
+				l.Fatal().Str("preset", presetName).Err(fmt.Errorf("preset is not defined")).Send()
+			}
+			preset = p
+			if !colorFlagChanged {
+				colorEnabled = preset.Color
+			}
+			if !twelveHourFlagChanged {
+				twelveHourEnabled = preset.TwelveHour
+			}
+		}
+
+		// load working hours from the config file and any preset, with any --working-hours flags taking precedence
+		wh, err := loadWorkingHours(append(preset.workingHourOverrides(), workingHoursFlag...))
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		workingHours = wh
+
+		// validate the am/pm style here, rather than in Args, since it is usually set in the config file
+		markers, err := parseAMPMStyle(ampmStyle)
+		if err != nil {
+			l.Fatal().Str("ampm-style", ampmStyle).Err(err).Send()
+		}
+		ampmMarkers = markers
+
+		// parse the region offset ranges here, rather than in Args, since they may be set in the config file
+		if groupRegionsEnabled {
+			ranges, err := parseGroupOffsets(groupOffsets)
+			if err != nil {
+				l.Fatal().Str("group-offsets", groupOffsets).Err(err).Send()
+			}
+			regionRanges = ranges
+		}
+
+		// parse the shading colors here, rather than in Args, since they may be set in the config file
+		colors, err := parseDayNightColors(nightColor, dawnColor, dayColor)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		shadeColors = colors
+		bands, err := parseHourColors(v.GetString("style.working_hours_color"), v.GetString("style.awake_color"), v.GetString("style.sleep_color"))
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		bandColors = bands
+		rc, err := getRowColors(v, rowColorFlag)
+		if err != nil {
+			l.Fatal().Strs("row-color", rowColorFlag).Err(err).Send()
+		}
+		rowColors = rc
+
+		// timezones from --timezone-file follow those given with --timezone. It's read here rather than in Args, so
+		// aliases in the config file can be used in it.
+		if cmd.Flags().Changed("timezone-file") {
+			fileTimezones, err := readTimezoneFile(timezoneFile)
+			if err != nil {
+				l.Fatal().Str("timezone-file", timezoneFile).Err(err).Send()
+			}
+			// without --timezone, the flag holds the timezones from the config file, which the file replaces
+			if !timezoneFlagChanged {
+				timezones = nil
+			}
+			timezones = append(timezones, fileTimezones...)
+			timezoneFlagChanged = true
+		}
+
+		// add the local timezone to the timezones given on the command line, unless --no-local is set. This is done here
+		// rather than in Args, so --no-local may also come from the config file or TIMEBUDDY_NO_LOCAL. Timezones from the
+		// config file already include it, if it was wanted when they were saved.
+		if !noLocal && (timezoneFlagChanged || len(timezones) == 0) {
+			timezones = deduplicateSlice(includeLocalTimezone(timezones))
+		}
+
+		// use the profile's timezones, unless timezones were given on the command line
+		if profile != "" {
+			key := "profiles." + strings.ToLower(profile)
+			if !v.IsSet(key) {
+				l.Fatal().Str("profile", profile).Err(fmt.Errorf("profile is not defined")).Send()
+			}
+			if !timezoneFlagChanged {
+				timezones = v.GetStringSlice(key)
+				if !noLocal {
+					timezones = includeLocalTimezone(timezones)
+				}
+				timezones = deduplicateSlice(timezones)
+			}
+		}
+
+		// use the preset's timezones, unless timezones were given on the command line
+		if presetName != "" && !timezoneFlagChanged {
+			timezones = preset.Timezones
+			if !noLocal {
+				timezones = includeLocalTimezone(timezones)
+			}
+			timezones = deduplicateSlice(timezones)
+		}
+
+		// timezones like america/new_york, and city names like Paris, are replaced by the timezone they name, so its
+		// canonical spelling is shown and saved
+		resolvedTimezones, err := resolveTimezoneNames(timezones)
+		if err != nil {
+			l.Fatal().Strs("timezone", timezones).Err(err).Send()
+		}
+		timezones = resolvedTimezones
+
+		// --highlight-now, and a date and time in --date, replace any highlight saved in the config file
+		if highlightNowEnabled {
+			highlightSpecs = nil
+		}
+		if dateHighlight != "" {
+			highlightSpecs = []string{dateHighlight}
+		}
+		// --highlight "" highlights nothing, and clears the saved highlight
+		highlightSpecs = slices.DeleteFunc(highlightSpecs, func(s string) bool { return strings.TrimSpace(s) == "" })
+
+		shownTimezones := timezones
+
+		// --without hides timezones for this run only, so they stay in the list saved to the config file
+		if len(withoutTimezones) > 0 {
+			var unknown []string
+			shownTimezones, unknown = excludeTimezones(shownTimezones, withoutTimezones)
+			for _, tz := range unknown {
+				if !quietEnabled {
+					fmt.Fprintln(os.Stderr, text.FgYellow.Sprintf("Warning: --without %q isn't one of the timezones shown", tz))
+				}
+			}
 		}
 
-		// loop over the timezones and get the details for each
+		// loading many timezones can be slow, i.e. from a network mounted home directory, so Ctrl+C stops it rather than
+		// waiting for it to finish
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		// with --ignore-errors, invalid timezones are skipped with a warning rather than exiting. They stay in the list
+		// saved to the config file.
 		var zones timezoneDetails
-		for _, z := range timezones {
-			zones = append(zones, getZoneInfo(z, date))
+		var zoneErrs []error
+		err = withSpinner("Loading time zones...", func() error {
+			if ignoreErrors {
+				zones, zoneErrs = processTimezonesLenient(ctx, shownTimezones, date, l)
+				return ctx.Err()
+			}
+			var err error
+			zones, err = processTimezones(ctx, shownTimezones, date, l)
+			return err
+		})
+		exitOnZoneError(err)
+		for _, err := range zoneErrs {
+			if !quietEnabled {
+				fmt.Fprintln(os.Stderr, text.FgYellow.Sprintf("Warning: skipping %v", err))
+			}
+		}
+
+		if highlightDuration < 0 {
+			l.Fatal().Int("duration", highlightDuration).Err(fmt.Errorf("duration can't be negative")).Send()
+		}
+		// a highlight longer than half the table no longer shows where the meeting is, so --duration is capped
+		if highlightDuration > maxHighlightDuration {
+			if !quietEnabled {
+				fmt.Fprintln(os.Stderr, text.FgYellow.Sprintf("Warning: --duration %d is longer than 12 hours, highlighting 12 hours", highlightDuration))
+			}
+			highlightDuration = maxHighlightDuration
+		}
+
+		// the highlighted offsets are checked against every timezone, including rows hidden by --only, and before
+		// they are saved with --save-highlight
+		highlightColumns, highlightTrailingColumns, highlightInstants, err = parseHighlightFlag(highlightSpecs, zones, date, highlightDuration)
+		if err != nil {
+			l.Fatal().Strs("highlight", highlightSpecs).Err(err).Send()
+		}
+
+		// write preferences to config file. With a profile, its timezones are saved instead of the default set. Values
+		// from the project config file and a preset aren't saved.
+		if !projectFlags["color"] && presetName == "" {
+			v.Set("color", colorEnabled)
+		}
+		v.Set("emoji", emojiEnabled)
+		v.Set("icon", iconsEnabled)
+		v.Set("shade", shadeEnabled)
+		v.Set("hour-bands", hourBandsEnabled)
+		if profile != "" {
+			v.Set("profiles."+strings.ToLower(profile), timezones)
+		} else if !projectFlags["timezone"] && presetName == "" {
+			v.Set("timezone", timezones)
+		}
+		limit := defaultRecentlyUsedLimit
+		if v.IsSet("recently_used_limit") {
+			limit = max(v.GetInt("recently_used_limit"), 0)
+		}
+		v.Set("recently_used", updateRecentlyUsed(v.GetStringSlice("recently_used"), timezones, limit))
+		if !projectFlags["twelve-hour"] && presetName == "" {
+			v.Set("twelve-hour", twelveHourEnabled)
+		}
+		clearHighlight := highlightFlagChanged && len(highlightSpecs) == 0
+		if saveHighlight && len(highlightSpecs) > 0 {
+			v.Set("highlight", highlightSpecs)
+		}
+		if saved, err := withoutEnvSettings(v); err != nil {
+			l.Error().Str("configFile", getConfigPath()).Err(err).Send()
+		} else if err := atomicWriteConfig(saved, getConfigPath()); err != nil {
+			l.Error().Str("viper", err.Error()).Send()
+		}
+		if clearHighlight {
+			// viper can't remove a key, so remove it from the file that was just written
+			if fv, err := readConfigFile(); err == nil && fv.IsSet("highlight") {
+				if err := removeConfigKey(fv, "highlight"); err != nil {
+					l.Error().Str("configFile", getConfigPath()).Err(err).Send()
+				}
+			}
+		}
+
+		// render only the requested rows, numbered by their position in the full list so they match what was shown
+		if cmd.Flags().Changed("only") {
+			selected, err := selectRows(zones, onlyRows)
+			if err != nil {
+				l.Fatal().Ints("only", onlyRows).Err(err).Send()
+			}
+			zones = selected
+		}
+
+		// write the output to --output instead of stdout
+		var out io.Writer = os.Stdout
+		if outputFile != "" {
+			f, err := openOutputFile(outputFile, appendOutput)
+			if err != nil {
+				l.Fatal().Str("output", outputFile).Err(err).Send()
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch format {
+		case "", "unix":
+		case "slack":
+			if len(rangeDates) > 0 {
+				l.Fatal().Str("format", format).Err(fmt.Errorf("--format can't be used with --from and --to")).Send()
+			}
+			refTime := timeNow()
+			if timeOfDay != "" {
+				refTime = specifiedTime(date)
+			} else if date != timeNow().Format(time.DateOnly) {
+				refTime, _ = time.ParseInLocation(time.DateOnly, date, time.Local)
+			}
+			fmt.Fprintln(out, renderSlack(zones, refTime))
+			return
+		default:
+			if len(rangeDates) > 0 {
+				l.Fatal().Str("format", format).Err(fmt.Errorf("--format can't be used with --from and --to")).Send()
+			}
+			if err := printFormat(out, zones, format); err != nil {
+				l.Fatal().Str("format", format).Err(err).Send()
+			}
+			return
+		}
+
+		// print the first day's table, then one for each other day in the range, separated by blank lines
+		printTimeTable(out, zones, colorEnabled)
+		for _, d := range rangeDates[min(1, len(rangeDates)):] {
+			date = d
+			var dayZones timezoneDetails
+			if ignoreErrors {
+				// the timezones that were skipped have already been warned about
+				dayZones, _ = processTimezonesLenient(ctx, shownTimezones, date, l)
+				err = ctx.Err()
+			} else {
+				dayZones, err = processTimezones(ctx, shownTimezones, date, l)
+			}
+			exitOnZoneError(err)
+			// offsets of timezones given by name may differ from day to day
+			highlightColumns, highlightTrailingColumns, highlightInstants, err = parseHighlightFlag(highlightSpecs, dayZones, date, highlightDuration)
+			if err != nil {
+				l.Fatal().Str("date", date).Strs("highlight", highlightSpecs).Err(err).Send()
+			}
+			if cmd.Flags().Changed("only") {
+				if dayZones, err = selectRows(dayZones, onlyRows); err != nil {
+					l.Fatal().Ints("only", onlyRows).Err(err).Send()
+				}
+			}
+			fmt.Fprintln(out)
+			printTimeTable(out, dayZones, colorEnabled)
+		}
+		if len(rangeDates) > 1 {
+			return
 		}
 
-		printTimeTable(zones, colorEnabled)
+		// suggest a call window under the table, by default only when exactly two zones are shown
+		if !cmd.Flags().Changed("suggest") {
+			suggestEnabled = len(zones) == 2
+		}
+		if suggestEnabled && len(zones) == 2 {
+			fmt.Fprintln(out, formatCallWindow(callZoneFromDetail(zones[0]), callZoneFromDetail(zones[1])))
+		}
 	},
 }
 
@@ -411,16 +2103,101 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.SetVersionTemplate(`{{printf "timeBuddy %s\n" .Version}}`)
+	rootCmd.SetVersionTemplate(formatVersion(buildinfo.Get()))
+	rootCmd.SetGlobalNormalizationFunc(normalizeLegacyFlags)
+	rootCmd.Flags().StringVar(&ampmStyle, "ampm-style", "lower", "``am/pm markers used with --twelve-hour: lower, upper, single, or a custom pair like vm/nm. Markers can be up to 3 characters wide.")
 	rootCmd.Flags().BoolVarP(&colorEnabled, "color", "c", false, "enable colorized table output. If previously enabled, use --color=false to disable it,")
-	rootCmd.Flags().StringVarP(&date, "date", "d", time.Now().Format(time.DateOnly), "``date to use for time conversion. Expects YYYY-MM-DD format. Defaults to current date/time.")
+	rootCmd.Flags().StringVarP(&date, "date", "d", time.Now().Format(time.DateOnly), "``date to use for time conversion. Expects YYYY-MM-DD format, also accepts 15.06.2024, 06/15/2024, or \"Jun 15 2024\", a date and time like 2024-11-05T15:00+11:00 or \"2024-11-05 15:00\" to also highlight that moment, a unix timestamp in seconds or milliseconds like --unix, today, tomorrow, yesterday, a weekday like monday for the next one, or a relative number of days or weeks like +7d, -3d, or +2w. Defaults to current date/time.")
+	rootCmd.Flags().BoolVar(&iconsEnabled, "icon", false, "prefix each row with the flag of the timezone's country, or 🌐 if it has none. An alias can set its own icon, see timeBuddy alias add --help. If previously enabled, use --no-icon to disable it. Also available as --flags.")
+	rootCmd.Flags().BoolVar(&iconsEnabled, "flags", false, "same as --icon")
+	if err := rootCmd.Flags().MarkHidden("flags"); err != nil {
+		l.Error().Err(err).Send()
+	}
+	if err := rootCmd.Flags().SetAnnotation("flags", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().BoolVar(&noIcon, "no-icon", false, "don't prefix rows with an icon, the same as --icon=false")
+	if err := rootCmd.Flags().SetAnnotation("no-icon", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().BoolVarP(&emojiEnabled, "emoji", "e", false, "prefix each row with a clock emoji showing the time in that timezone. Some terminals render emoji double-width, which may misalign the table. If previously enabled, use --emoji=false to disable it.")
+	rootCmd.Flags().BoolVar(&followLinks, "follow-links", true, "show the canonical name of timezones that are links to another timezone, i.e. America/New_York rather than US/Eastern. Use --follow-links=false to show the name as given.")
+	rootCmd.Flags().StringVarP(&format, "format", "f", "", "``Go text/template used to print the time zones instead of a table, slack for a single line suitable for pasting into Slack, or unix to add a row with the unix timestamp of each hour to the table. See examples above.")
+	rootCmd.Flags().BoolVar(&noEmoji, "no-emoji", false, "don't prefix time zones with country flag emoji in slack format")
+	rootCmd.Flags().StringVar(&nightColor, "night-color", defaultNightBg, "``background color of night hours, 22:00-06:59, with --shade. Accepts a color code from 0 to 255 or #RRGGBB.")
+	rootCmd.Flags().StringVar(&dawnColor, "dawn-color", defaultDawnBg, "``background color of dawn and evening hours, 07:00-08:59 and 18:00-21:59, with --shade. Accepts a color code from 0 to 255 or #RRGGBB.")
+	rootCmd.Flags().StringVar(&dayColor, "day-color", "", "``background color of day hours, 09:00-17:59, with --shade. Accepts a color code from 0 to 255 or #RRGGBB. Defaults to the normal background.")
+	rootCmd.Flags().StringArrayVarP(&highlightSpecs, "highlight", "H", []string{}, "``hour to highlight, followed by the UTC offset or timezone it is in, i.e. 15+11 for 3pm at UTC+11, or 15@Australia/Sydney for 3pm in Sydney on --date. The time may also be a 12-hour time like 3pm+11. A bare time like 15 is in your local timezone, use 15+0 for UTC. A range of hours like 15-17+11 highlights 3pm up to 5pm. Can be used multiple times.")
+	rootCmd.Flags().IntVar(&highlightDuration, "duration", 0, "``length in minutes of each --highlight of a single time, i.e. --highlight 15+11 --duration 120 highlights 3pm up to 5pm. The columns after the first are a lighter color. Capped at 12 hours.")
+	rootCmd.Flags().BoolVarP(&numberedEnabled, "numbered", "n", false, "prefix each row with its number, for use with --only")
+	rootCmd.Flags().StringVar(&timezoneFile, "timezone-file", "", "``file of timezones to use, one per line, or - to read them from stdin. Blank lines and lines starting with # are ignored. The timezones follow any given with --timezone.")
+	rootCmd.Flags().StringArrayVar(&withoutTimezones, "without", []string{}, "``timezone to leave out of the table for this run, without removing it from the config file. Can be used multiple times.")
+	if err := rootCmd.RegisterFlagCompletionFunc("without", completeTimezone); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().IntSliceVar(&onlyRows, "only", []int{}, "``comma separated list of row numbers to show, i.e. 2,5. Rows are numbered as shown by --numbered.")
+	rootCmd.Flags().StringVarP(&profile, "profile", "p", "", "``profile whose timezones are shown instead of those saved in the config file. Any changes are saved to the profile. See timeBuddy profile --help.")
+	if err := rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().StringVar(&presetName, "preset", "", "``preset whose timezones, color and 12-hour settings, and working hours are used for this run, without changing the config file. See timeBuddy preset --help.")
+	if err := rootCmd.RegisterFlagCompletionFunc("preset", completePresets); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().BoolVarP(&relativeColumnEnabled, "relative-column", "r", false, "add a column showing each timezone's offset relative to the local timezone, or the one set with --relative-to")
+	rootCmd.Flags().StringVar(&relativeTo, "relative-to", "Local", "``timezone the relative column is calculated from. Defaults to the local timezone.")
+	rootCmd.Flags().StringArrayVarP(&workingHoursFlag, "working-hours", "w", []string{}, "``working hours for a timezone as timezone=HH:MM-HH:MM, i.e. America/New_York=09:00-17:00. Hours outside of working hours are dimmed. Can be used multiple times.")
+	rootCmd.Flags().BoolVar(&hourBandsEnabled, "hour-bands", false, "color each hour by whether it is in the timezone's working hours, 09:00-17:00 unless set with --working-hours, awake, 06:00-22:59, or sleeping. The colors are set with style.working_hours_color, style.awake_color, and style.sleep_color in the config file. Without --color, hours are prefixed with █ working, ▒ awake, or ░ sleeping. Takes precedence over --shade. If previously enabled, use --hour-bands=false to disable it.")
+	rootCmd.Flags().StringArrayVar(&rowColorFlag, "row-color", []string{}, "``text color of a timezone's row as timezone=color, i.e. America/New_York=#FF0000, with --color. Accepts a color code from 0 to 255 or #RRGGBB, and overrides the row_colors map in the config file. Can be used multiple times.")
+	rootCmd.Flags().BoolVarP(&shadeEnabled, "shade", "s", false, "shade each hour by the time of day in its timezone. Uses --night-color, --dawn-color, and --day-color with --color, otherwise a line of block characters: ▓ night, ▒ dawn and evening, ░ day. If previously enabled, use --shade=false to disable it.")
+	rootCmd.Flags().BoolVar(&suggestEnabled, "suggest", false, "print the best call window within working hours under the table. Only applies to exactly two timezones, and is enabled by default for them.")
+	if err := rootCmd.Flags().SetAnnotation("suggest", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().BoolVar(&highlightNowEnabled, "highlight-now", false, "highlight the current hour even when --date isn't today, i.e. to compare it either side of a DST change. Can't be used with --highlight, --time, or --unix.")
+	if err := rootCmd.Flags().SetAnnotation("highlight-now", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().StringVar(&nowOverride, "now", "", "``pretend the current time is this date and time, like 2024-06-15T14:00:00Z, or this time of day today, like 14:00, i.e. for demos and screenshots. The title, each row's current time, and the highlighted hour use it.")
+	if err := rootCmd.Flags().SetAnnotation("now", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().BoolVar(&groupRegionsEnabled, "group-regions", false, "group the timezones by region, with a row naming the region above each group. Timezones keep their order, so list those in a region together.")
+	rootCmd.Flags().StringVar(&groupOffsets, "group-offsets", defaultGroupOffsets, "``comma separated ranges of whole hour UTC offsets, from:to, that --group-regions groups timezones by. The default ranges are named Americas, Atlantic/Europe/Africa, Middle East/Asia, and Pacific, others are named by their offsets.")
+	rootCmd.Flags().StringVar(&rangeFrom, "from", "", "``first day of a range of days to show a table for each of, with --to. Accepts the same values as --date.")
+	rootCmd.Flags().StringVar(&rangeTo, "to", "", "``last day of a range of days to show a table for each of, with --from. Accepts the same values as --date. The range can be up to 14 days.")
+	for _, name := range []string{"from", "to"} {
+		if err := rootCmd.Flags().SetAnnotation(name, skipConfigAnnotation, []string{"true"}); err != nil {
+			l.Error().Err(err).Send()
+		}
+	}
+	rootCmd.Flags().BoolVar(&ignoreErrors, "ignore-errors", false, "skip invalid timezones with a warning, and show the rest, instead of exiting")
+	rootCmd.Flags().StringVar(&outputFile, "output", "", "``file to write the output to instead of stdout. The file is replaced unless --append is provided.")
+	rootCmd.Flags().BoolVar(&appendOutput, "append", false, "append to the file given with --output instead of replacing it")
+	for _, name := range []string{"output", "append"} {
+		if err := rootCmd.Flags().SetAnnotation(name, skipConfigAnnotation, []string{"true"}); err != nil {
+			l.Error().Err(err).Send()
+		}
+	}
+	rootCmd.Flags().BoolVar(&saveHighlight, "save-highlight", false, "save --highlight in the config file, so it's applied whenever --highlight isn't provided. Use --highlight \"\" to clear it.")
+	if err := rootCmd.Flags().SetAnnotation("save-highlight", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	rootCmd.Flags().StringVarP(&timeOfDay, "time", "T", "", "``time of day to use for time conversion, in your local timezone. Expects 24-hour HH:MM format, a 12-hour time like 3pm or 3:30pm, noon, midnight, or now.")
+	rootCmd.Flags().StringVar(&unixTime, "unix", "", "``unix timestamp to use for time conversion, in seconds or milliseconds. Implies --date and --time, so it can't be used with either.")
+	rootCmd.MarkFlagsMutuallyExclusive("unix", "date")
+	rootCmd.MarkFlagsMutuallyExclusive("unix", "time")
 	rootCmd.Flags().BoolVarP(&twelveHourEnabled, "twelve-hour", "t", false, "use 12-hour time format instead of 24-hour. If previously enabled, use --twelve-hour=false to disable it.")
+	rootCmd.PersistentFlags().BoolVar(&noProjectConfig, "no-project-config", false, "don't look for a project config file in the working directory or the directories above it")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "load every timezone from tzdata and look up its offset each time it's used, instead of caching them until the next offset change. For debugging.")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "``config file to use instead of the default, created if it doesn't exist. Can also be set with TIMEBUDDY_CONFIG.")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "``file to append log output to instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "``log output format, text or json")
 	rootCmd.PersistentFlags().CountP("verbose", "v", "``increase logging verbosity, 1=warn, 2=info, 3=debug, 4=trace")
-	rootCmd.Flags().BoolP("exclude-local", "x", false, "disable default behavior of including local timezone in output")
-	rootCmd.Flags().StringArrayVarP(&timezones, "timezone", "z", []string{}, "``timezone to use for time conversion. Accepts timezone name, like America/New_York. Can be used multiple times.")
-	err := rootCmd.RegisterFlagCompletionFunc("timezone", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return timezonesAll, cobra.ShellCompDirectiveDefault
-	})
+	rootCmd.PersistentFlags().BoolVarP(&quietEnabled, "quiet", "q", false, "only print the output, without notices, warnings, or the loading spinner, i.e. when piping it to another command. Fatal errors are still printed. --verbose takes precedence.")
+	rootCmd.Flags().BoolVarP(&noLocal, "no-local", "x", false, "disable default behavior of including local timezone in output. Formerly --exclude-local, which still works.")
+	rootCmd.Flags().StringArrayVarP(&timezones, "timezone", "z", []string{}, "``timezone to use for time conversion. Accepts timezone name, like America/New_York, or city, like Paris. Can be used multiple times, or given a comma separated list like UTC,Asia/Tokyo.")
+	err := rootCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
 	if err != nil {
 		l.Error().Err(err).Send()
 	}
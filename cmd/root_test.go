@@ -0,0 +1,1076 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JakeTRogers/timeBuddy/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// useTestConfig points the config file at a file in a temporary directory holding content, or at a file that doesn't
+// exist yet if content is empty, and returns its path. HOME is moved to the temporary directory too, and the project
+// config file and onboarding are turned off, so the user's own files are never read or written.
+func useTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", dir)
+	t.Setenv("TIMEBUDDY_CONFIG", "")
+	t.Setenv("TIMEBUDDY_SKIP_ONBOARDING", "1")
+
+	oldConfigFile, oldNoProjectConfig := configFile, noProjectConfig
+	configFile, noProjectConfig = path, true
+	projectConfig, projectFlags, envFlags = nil, map[string]bool{}, map[string]bool{}
+	t.Cleanup(func() {
+		configFile, noProjectConfig = oldConfigFile, oldNoProjectConfig
+		projectConfig, projectFlags, envFlags = nil, map[string]bool{}, map[string]bool{}
+	})
+	return path
+}
+
+// newTestCommand returns a command with a --timezone flag like the root command's, for binding the config to.
+func newTestCommand() (*cobra.Command, *[]string) {
+	var tzs []string
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringArrayVarP(&tzs, "timezone", "z", []string{}, "")
+	return cmd, &tzs
+}
+
+// executeCommand runs timeBuddy with args and returns what it wrote to stdout. The flags of every command and the
+// package viper instance are reset before the run and after the test, so each run starts fresh.
+func executeCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	resetFlags(rootCmd)
+	oldViper := v
+	v = viper.New()
+	t.Cleanup(func() {
+		v = oldViper
+		resetFlags(rootCmd)
+		rootCmd.SetArgs(nil)
+	})
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	captured := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		captured <- string(b)
+	}()
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	os.Stdout = old
+	w.Close()
+	out := <-captured
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// resetFlags sets each flag of the command and its subcommands that was changed, on the command line or from the
+// config file, back to its default.
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// captureStderr returns what f writes to stderr, including log messages.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stderr
+	os.Stderr = w
+	logger.SetOutput(w)
+	defer func() {
+		os.Stderr = old
+		logger.SetOutput(old)
+	}()
+
+	captured := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		captured <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-captured
+}
+
+func Test_bindFlags_environment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want []string
+	}{
+		{
+			name: "config file",
+			want: []string{"Asia/Tokyo"},
+		},
+		{
+			name: "TIMEBUDDY_TIMEZONES takes precedence over the config file",
+			env:  map[string]string{"TIMEBUDDY_TIMEZONES": "UTC,Europe/Berlin"},
+			want: []string{"UTC", "Europe/Berlin"},
+		},
+		{
+			name: "TIMEBUDDY_TIMEZONE is split on colons",
+			env:  map[string]string{"TIMEBUDDY_TIMEZONE": "UTC:Europe/Berlin"},
+			want: []string{"UTC", "Europe/Berlin"},
+		},
+		{
+			name: "offsets aren't split on their colon",
+			env:  map[string]string{"TIMEBUDDY_TIMEZONES": "UTC+05:30,Europe/Berlin"},
+			want: []string{"UTC+05:30", "Europe/Berlin"},
+		},
+		{
+			name: "flags take precedence over the environment",
+			env:  map[string]string{"TIMEBUDDY_TIMEZONES": "UTC,Europe/Berlin"},
+			args: []string{"-z", "America/New_York"},
+			want: []string{"America/New_York"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, "timezone:\n  - Asia/Tokyo\n")
+			for k, val := range tt.env {
+				t.Setenv(k, val)
+			}
+			cmd, tzs := newTestCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := initializeConfig(cmd, viper.New(), l); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(*tzs, tt.want) {
+				t.Errorf("timezone = %v, want %v", *tzs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withoutEnvSettings(t *testing.T) {
+	path := useTestConfig(t, "timezone:\n  - Asia/Tokyo\n")
+	t.Setenv("TIMEBUDDY_TIMEZONES", "Europe/Berlin")
+	t.Setenv("TIMEBUDDY_TWELVE_HOUR", "true")
+	cmd, _ := newTestCommand()
+	var twelveHour bool
+	cmd.Flags().BoolVar(&twelveHour, "twelve-hour", false, "")
+	cv := viper.New()
+	if err := initializeConfig(cmd, cv, l); err != nil {
+		t.Fatal(err)
+	}
+	if !envFlags["timezone"] || !envFlags["twelve-hour"] {
+		t.Fatalf("envFlags = %v, want timezone and twelve-hour", envFlags)
+	}
+
+	saved, err := withoutEnvSettings(cv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := atomicWriteConfig(saved, path); err != nil {
+		t.Fatal(err)
+	}
+	fv, err := readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fv.GetStringSlice("timezone"); !slices.Equal(got, []string{"Asia/Tokyo"}) {
+		t.Errorf("saved timezone = %v, want [Asia/Tokyo]", got)
+	}
+	if fv.IsSet("twelve-hour") {
+		t.Errorf("saved twelve-hour = %v, want it unset", fv.Get("twelve-hour"))
+	}
+}
+
+func Test_initializeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		// wantSchemaVersion is the schema_version of the config file afterwards
+		wantSchemaVersion int
+	}{
+		{
+			name:              "missing config file is created",
+			want:              []string{},
+			wantSchemaVersion: currentSchemaVersion,
+		},
+		{
+			name:              "config file is read",
+			content:           "schema_version: 1\ntimezone:\n  - Asia/Tokyo\n  - Europe/London\n",
+			want:              []string{"Asia/Tokyo", "Europe/London"},
+			wantSchemaVersion: 1,
+		},
+		{
+			name:              "old config file is migrated",
+			content:           "timezone:\n  - Asia/Tokyo\n",
+			want:              []string{"Asia/Tokyo"},
+			wantSchemaVersion: currentSchemaVersion,
+		},
+		{
+			name:              "legacy spelling is read",
+			content:           "schema_version: 1\ntz:\n  - Asia/Tokyo\n",
+			want:              []string{"Asia/Tokyo"},
+			wantSchemaVersion: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTestConfig(t, tt.content)
+			cmd, tzs := newTestCommand()
+			cv := viper.New()
+			if err := initializeConfig(cmd, cv, l); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(*tzs, tt.want) {
+				t.Errorf("timezone = %v, want %v", *tzs, tt.want)
+			}
+			if got := cv.ConfigFileUsed(); got != path {
+				t.Errorf("ConfigFileUsed() = %q, want %q", got, path)
+			}
+			// the package's own viper instance isn't touched
+			if v.ConfigFileUsed() == path {
+				t.Errorf("package viper instance read %q", path)
+			}
+			fv, err := readConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fv.GetInt("schema_version"); got != tt.wantSchemaVersion {
+				t.Errorf("schema_version = %d, want %d", got, tt.wantSchemaVersion)
+			}
+		})
+	}
+}
+
+func Test_SprintTimeTable(t *testing.T) {
+	const day = "2024-06-15"
+	zones, err := processTimezones(context.Background(), []string{"UTC", "Asia/Tokyo"}, day, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDate, oldTwelveHour := date, twelveHourEnabled
+
+	tests := []struct {
+		name       string
+		twelveHour bool
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:    "24-hour",
+			want:    []string{"Showing Time For: Saturday, June 15, 2024", "UTC", "Asia/Tokyo", "23"},
+			notWant: []string{"pm"},
+		},
+		{
+			name:       "12-hour",
+			twelveHour: true,
+			want:       []string{"Showing Time For: Saturday, June 15, 2024", "Asia/Tokyo", "am", "pm"},
+			notWant:    []string{"23"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SprintTimeTable(zones, false, 9, tt.twelveHour, day)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("SprintTimeTable() doesn't contain %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("SprintTimeTable() contains %q:\n%s", s, got)
+				}
+			}
+			if date != oldDate || twelveHourEnabled != oldTwelveHour || highlightColumns != nil {
+				t.Errorf("SprintTimeTable() didn't restore the table state")
+			}
+		})
+	}
+}
+
+func Test_SprintTimeTable_ampmStyle(t *testing.T) {
+	const day = "2024-06-15"
+	// Kolkata's half hour offset and day change make its columns differ from UTC's
+	zones, err := processTimezones(context.Background(), []string{"UTC", "Asia/Kolkata"}, day, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldMarkers := ampmMarkers
+	t.Cleanup(func() { ampmMarkers = oldMarkers })
+
+	// tokenEnds returns the rune index each space separated token in line ends at
+	tokenEnds := func(line string) []int {
+		var ends []int
+		runes := []rune(line)
+		for i, r := range runes {
+			if r != ' ' && (i+1 == len(runes) || runes[i+1] == ' ') {
+				ends = append(ends, i)
+			}
+		}
+		return ends
+	}
+
+	for _, style := range []string{"lower", "upper", "single", "a.m/p.m", "a/pm"} {
+		t.Run(style, func(t *testing.T) {
+			markers, err := parseAMPMStyle(style)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ampmMarkers = markers
+			lines := strings.Split(SprintTimeTable(zones, false, -1, true, day), "\n")
+
+			rows := 0
+			for i, line := range lines {
+				// each row is the line with the hours, below its label, followed by the line with the markers
+				if !strings.Contains(line, "[") || i+1 == len(lines) {
+					continue
+				}
+				rows++
+				hourEnds := tokenEnds(line)
+				markerLine := lines[i+1]
+				count := strings.Count(markerLine, " "+markers[0]+" ") + strings.Count(markerLine, " "+markers[1]+" ")
+				if count < 23 {
+					t.Errorf("row %q has %d markers, want one under each hour:\n%s", line, count, markerLine)
+				}
+				// each marker is right aligned with the hour above it
+				for _, end := range tokenEnds(markerLine)[1:] {
+					if end == len([]rune(markerLine))-1 {
+						continue // the closing border
+					}
+					if !slices.Contains(hourEnds, end) {
+						t.Errorf("marker ending at column %d isn't aligned with an hour:\n%s\n%s", end, line, markerLine)
+					}
+				}
+			}
+			if rows != len(zones) {
+				t.Errorf("table has %d rows, want %d", rows, len(zones))
+			}
+		})
+	}
+}
+
+func Test_SRenderTimeTable(t *testing.T) {
+	const day = "2024-06-15"
+	tests := []struct {
+		name      string
+		timezones []string
+		highlight string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "timezones",
+			timezones: []string{"UTC", "Europe/London"},
+			want:      []string{"Showing Time For: Saturday, June 15, 2024", "UTC", "Europe/London"},
+		},
+		{
+			name:      "highlight part way through an hour",
+			timezones: []string{"UTC", "Asia/Kolkata"},
+			highlight: "15:00+5:30",
+			want:      []string{"Asia/Kolkata", "(highlight = 09:30 UTC)"},
+		},
+		{
+			name:      "invalid timezone",
+			timezones: []string{"Not/AZone"},
+			wantErr:   true,
+		},
+		{
+			name:      "highlight offset not in the table",
+			timezones: []string{"UTC"},
+			highlight: "15+11",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SRenderTimeTable(&cobra.Command{}, l, tt.timezones, day, false, false, tt.highlight)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SRenderTimeTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("SRenderTimeTable() doesn't contain %q:\n%s", s, got)
+				}
+			}
+			if highlightColumns != nil || highlightInstants != nil {
+				t.Errorf("SRenderTimeTable() didn't restore the highlights")
+			}
+		})
+	}
+}
+
+func Test_processTimezonesLenient(t *testing.T) {
+	zones, errs := processTimezonesLenient(context.Background(), []string{"UTC", "Bad/Zone", "Asia/Tokyo", "Also/Bad"}, "2024-06-15", l)
+	var names []string
+	var indexes []int
+	for _, z := range zones {
+		names = append(names, z.name)
+		indexes = append(indexes, z.index)
+	}
+	if want := []string{"UTC", "Asia/Tokyo"}; !slices.Equal(names, want) {
+		t.Errorf("zones = %v, want %v", names, want)
+	}
+	if want := []int{1, 2}; !slices.Equal(indexes, want) {
+		t.Errorf("indexes = %v, want %v", indexes, want)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "Bad/Zone") || !strings.Contains(errs[1].Error(), "Also/Bad") {
+		t.Errorf("errs = %v, want one for Bad/Zone and one for Also/Bad", errs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if zones, errs := processTimezonesLenient(ctx, []string{"UTC"}, "2024-06-15", l); zones != nil || errs != nil {
+		t.Errorf("processTimezonesLenient() with a cancelled context = %v, %v, want nothing", zones, errs)
+	}
+}
+
+func Test_initializeConfig_missingDirectory(t *testing.T) {
+	tests := []struct {
+		name string
+		// env is true if the config file is given with TIMEBUDDY_CONFIG rather than --config
+		env bool
+	}{
+		{name: "--config"},
+		{name: "TIMEBUDDY_CONFIG", env: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, "")
+			path := filepath.Join(t.TempDir(), "nested", "dir", "timebuddy.yaml")
+			if tt.env {
+				configFile = ""
+				t.Setenv("TIMEBUDDY_CONFIG", path)
+			} else {
+				configFile = path
+			}
+			cmd, _ := newTestCommand()
+			if err := initializeConfig(cmd, viper.New(), l); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("config file wasn't created: %v", err)
+			}
+		})
+	}
+}
+
+// benchmarkTimezones are the 20 timezones the timezone loading benchmarks look up.
+var benchmarkTimezones = []string{
+	"UTC", "America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles",
+	"America/Sao_Paulo", "Europe/London", "Europe/Berlin", "Europe/Vilnius", "Africa/Lagos",
+	"Africa/Johannesburg", "Asia/Dubai", "Asia/Kolkata", "Asia/Kathmandu", "Asia/Shanghai",
+	"Asia/Tokyo", "Australia/Adelaide", "Australia/Sydney", "Pacific/Auckland", "Pacific/Honolulu",
+}
+
+func Test_processTimezones(t *testing.T) {
+	// run with -race, since each timezone is looked up in its own goroutine
+	zones, err := processTimezones(context.Background(), benchmarkTimezones, "2024-06-15", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != len(benchmarkTimezones) {
+		t.Fatalf("got %d zones, want %d", len(zones), len(benchmarkTimezones))
+	}
+	for i, z := range zones {
+		if z.name != benchmarkTimezones[i] || z.index != i+1 {
+			t.Errorf("zones[%d] = %s with index %d, want %s with index %d", i, z.name, z.index, benchmarkTimezones[i], i+1)
+		}
+	}
+
+	if _, err := processTimezones(context.Background(), []string{"UTC", "Bad/Zone"}, "2024-06-15", l); err == nil {
+		t.Errorf("processTimezones() with an invalid timezone error = nil, want an error")
+	}
+}
+
+// Benchmark_processTimezones compares looking up the timezones one at a time with processTimezones. Locations are
+// cached after the first iteration, so it mostly measures the rest of getZoneInfo and the cost of the goroutines.
+func Benchmark_processTimezones(b *testing.B) {
+	ctx := context.Background()
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, tz := range benchmarkTimezones {
+				if _, err := getZoneInfo(ctx, tz, "2024-06-15", l); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := processTimezones(ctx, benchmarkTimezones, "2024-06-15", l); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func Test_selectRows(t *testing.T) {
+	// rows are numbered after invalid timezones are skipped, so the numbers match the rows shown
+	zones, _ := processTimezonesLenient(context.Background(), []string{"UTC", "Bad/Zone", "Asia/Tokyo", "Europe/London"}, "2024-06-15", l)
+
+	tests := []struct {
+		name    string
+		rows    []int
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "rows in the order requested",
+			rows: []int{3, 1},
+			want: []string{"Europe/London", "UTC"},
+		},
+		{
+			name: "single row",
+			rows: []int{2},
+			want: []string{"Asia/Tokyo"},
+		},
+		{
+			name:    "row 0",
+			rows:    []int{0},
+			wantErr: true,
+		},
+		{
+			name:    "row past the end",
+			rows:    []int{1, 4},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectRows(zones, tt.rows)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for i, z := range got {
+				names = append(names, z.name)
+				// the row keeps the number it was shown with
+				if z.index != tt.rows[i] {
+					t.Errorf("row %s index = %d, want %d", z.name, z.index, tt.rows[i])
+				}
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("selectRows() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// with --numbered, the selected rows are labelled with the numbers they were selected by
+	old := numberedEnabled
+	numberedEnabled = true
+	t.Cleanup(func() { numberedEnabled = old })
+	selected, err := selectRows(zones, []int{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SprintTimeTable(selected, false, -1, false, "2024-06-15"); !strings.Contains(got, "3. Europe/London") {
+		t.Errorf("table doesn't contain row 3. Europe/London:\n%s", got)
+	}
+}
+
+func Test_splitTimezoneList(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{
+			name:   "a list follows the timezones before it",
+			values: []string{"America/New_York", "Europe/Vilnius,Australia/Sydney"},
+			want:   []string{"America/New_York", "Europe/Vilnius", "Australia/Sydney"},
+		},
+		{
+			name:   "spaces and empty entries are dropped",
+			values: []string{" UTC , ,Asia/Tokyo,", "Europe/London"},
+			want:   []string{"UTC", "Asia/Tokyo", "Europe/London"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitTimezoneList(tt.values); !slices.Equal(got, tt.want) {
+				t.Errorf("splitTimezoneList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rootCmd_timezoneList(t *testing.T) {
+	useTestConfig(t, "")
+	out := executeCommand(t, "-x", "-z", "America/New_York", "-z", "Europe/Vilnius,Australia/Sydney")
+
+	want := []string{"America/New_York", "Europe/Vilnius", "Australia/Sydney"}
+	// the rows are shown in the order given
+	var last int
+	for _, tz := range want {
+		i := strings.Index(out, tz)
+		if i < last {
+			t.Fatalf("%s isn't shown after the timezones before it:\n%s", tz, out)
+		}
+		last = i
+	}
+	fv, err := readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fv.GetStringSlice("timezone"); !slices.Equal(got, want) {
+		t.Errorf("saved timezone = %v, want %v", got, want)
+	}
+}
+
+func Test_processTimezones_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := getZoneInfo(ctx, "UTC", "2024-06-15", l); !errors.Is(err, context.Canceled) {
+		t.Errorf("getZoneInfo() error = %v, want context.Canceled", err)
+	}
+	zones, err := processTimezones(ctx, benchmarkTimezones, "2024-06-15", l)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("processTimezones() error = %v, want context.Canceled", err)
+	}
+	if zones != nil {
+		t.Errorf("processTimezones() = %d zones, want none", len(zones))
+	}
+	// cancelling wins over an invalid timezone, so Ctrl+C always exits quietly
+	if _, err := processTimezones(ctx, []string{"Bad/Zone"}, "2024-06-15", l); !errors.Is(err, context.Canceled) {
+		t.Errorf("processTimezones() with an invalid timezone error = %v, want context.Canceled", err)
+	}
+}
+
+func Test_processTimezones_cancelledPartWay(t *testing.T) {
+	// cancel while the timezones are being looked up, as Ctrl+C would
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var once sync.Once
+	old := timeNow
+	timeNow = func() time.Time {
+		once.Do(cancel)
+		return old()
+	}
+	t.Cleanup(func() { timeNow = old })
+
+	if _, err := processTimezones(ctx, benchmarkTimezones, "2024-06-15", l); !errors.Is(err, context.Canceled) {
+		t.Errorf("processTimezones() error = %v, want context.Canceled", err)
+	}
+}
+
+func Test_setupLogging_quiet(t *testing.T) {
+	// a legacy flag spelling, a renamed flag, an invalid timezone skipped with a warning, and an error each write to
+	// stderr, unless --quiet is given. --verbose takes precedence over --quiet, so it is only given without it, to show
+	// the warning about the renamed flag.
+	args := []string{"--tz", "UTC", "--exclude-local", "-z", "Bad/Zone", "--ignore-errors"}
+	tests := []struct {
+		name      string
+		args      []string
+		wantEmpty bool
+	}{
+		{name: "without --quiet", args: append(slices.Clone(args), "-v")},
+		{name: "--quiet", args: append(slices.Clone(args), "--quiet"), wantEmpty: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, "")
+			t.Cleanup(func() {
+				legacyFlagsUsed, renamedFlagsUsed = nil, map[string]bool{}
+				logger.SetLogLevel(0)
+			})
+			stderr := captureStderr(t, func() {
+				executeCommand(t, tt.args...)
+				l.Error().Msg("error after setupLogging")
+			})
+			if tt.wantEmpty && stderr != "" {
+				t.Errorf("stderr = %q, want it empty", stderr)
+			}
+			if !tt.wantEmpty {
+				for _, s := range []string{"--tz is a legacy spelling", "Flag has been renamed", "Warning: skipping", "error after setupLogging"} {
+					if !strings.Contains(stderr, s) {
+						t.Errorf("stderr doesn't contain %q:\n%s", s, stderr)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_normalizeLegacyFlags(t *testing.T) {
+	tests := []struct {
+		legacy    string
+		args      []string
+		canonical string
+		want      string
+	}{
+		{legacy: "tz", args: []string{"--tz", "UTC"}, canonical: "timezone", want: "[UTC]"},
+		{legacy: "12h", args: []string{"-z", "UTC", "--12h"}, canonical: "twelve-hour", want: "true"},
+		{legacy: "hl", args: []string{"-z", "UTC", "--hl", "15+0"}, canonical: "highlight", want: "[15+0]"},
+	}
+	for _, tt := range tests {
+		for _, verbose := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s verbose=%v", tt.legacy, verbose), func(t *testing.T) {
+				useTestConfig(t, "")
+				t.Cleanup(func() {
+					legacyFlagsUsed, renamedFlagsUsed = nil, map[string]bool{}
+					logger.SetLogLevel(0)
+				})
+				args := append([]string{"-x", "-d", "2024-06-15"}, tt.args...)
+				if verbose {
+					args = append(args, "-v")
+				}
+				stderr := captureStderr(t, func() {
+					executeCommand(t, args...)
+				})
+
+				f := rootCmd.Flags().Lookup(tt.canonical)
+				if !f.Changed || f.Value.String() != tt.want {
+					t.Errorf("--%s = %s, changed %v, want %s", tt.canonical, f.Value.String(), f.Changed, tt.want)
+				}
+				notice := fmt.Sprintf("Notice: --%s is a legacy spelling, use --%s instead.", tt.legacy, tt.canonical)
+				if got := strings.Contains(stderr, notice); got != verbose {
+					t.Errorf("stderr contains the notice = %v, want %v:\n%s", got, verbose, stderr)
+				}
+				// the alias is never written to the config file
+				fv, err := readConfigFile()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fv.IsSet(tt.legacy) {
+					t.Errorf("config file has the legacy key %s", tt.legacy)
+				}
+			})
+		}
+	}
+}
+
+func Test_formatRelativeOffset(t *testing.T) {
+	tests := []struct {
+		name          string
+		offsetMinutes int
+		baseMinutes   int
+		want          string
+	}{
+		{name: "ahead", offsetMinutes: 9 * 60, baseMinutes: 60, want: "+8"},
+		{name: "behind", offsetMinutes: -5 * 60, baseMinutes: 60, want: "-6"},
+		{name: "same offset", offsetMinutes: 60, baseMinutes: 60, want: "+0"},
+		{name: "45 minutes ahead", offsetMinutes: 12*60 + 45, baseMinutes: 12 * 60, want: "+0:45"},
+		{name: "45 minutes behind", offsetMinutes: 12 * 60, baseMinutes: 12*60 + 45, want: "-0:45"},
+		{name: "hours and 45 minutes", offsetMinutes: 5*60 + 45, baseMinutes: 0, want: "+5:45"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRelativeOffset(timezoneDetail{offsetMinutes: tt.offsetMinutes}, tt.baseMinutes); got != tt.want {
+				t.Errorf("formatRelativeOffset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newTimeTable_relativeColumn(t *testing.T) {
+	oldEnabled, oldRelativeTo := relativeColumnEnabled, relativeTo
+	relativeColumnEnabled, relativeTo = true, "Pacific/Auckland"
+	t.Cleanup(func() { relativeColumnEnabled, relativeTo = oldEnabled, oldRelativeTo })
+
+	// on 2024-06-15 Auckland is at UTC+12, and Chatham at UTC+12:45
+	got, err := SRenderTimeTable(nil, l, []string{"Pacific/Auckland", "Pacific/Chatham", "Asia/Tokyo", "America/New_York"}, "2024-06-15", false, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got, "\n")
+	for _, tt := range []struct{ zone, want string }{
+		{"Pacific/Auckland", "+0"},
+		{"Pacific/Chatham", "+0:45"},
+		{"Asia/Tokyo", "-3"},
+		{"America/New_York", "-16"},
+	} {
+		i := slices.IndexFunc(lines, func(line string) bool { return strings.Contains(line, tt.zone) })
+		if i < 0 {
+			t.Fatalf("table doesn't contain %s:\n%s", tt.zone, got)
+		}
+		// the relative column follows the row label, which ends with the abbreviation and offset in brackets
+		_, rest, _ := strings.Cut(lines[i], "]")
+		if fields := strings.Fields(rest); len(fields) == 0 || fields[0] != tt.want {
+			t.Errorf("relative offset of %s in %q, want %q", tt.zone, lines[i], tt.want)
+		}
+	}
+}
+
+func Test_parseLocaleDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		dateFormat string
+		s          string
+		want       string
+		wantErr    bool
+	}{
+		{name: "ambiguous without date-format", s: "03/04/2024", wantErr: true},
+		{name: "ambiguous month first", dateFormat: "mdy", s: "03/04/2024", want: "2024-03-04"},
+		{name: "ambiguous day first", dateFormat: "dmy", s: "03/04/2024", want: "2024-04-03"},
+		{name: "ambiguous day first in upper case", dateFormat: "DMY", s: "03/04/2024", want: "2024-04-03"},
+		{name: "same month and day", s: "04/04/2024", want: "2024-04-04"},
+		{name: "day can't be a month", s: "15/06/2024", want: "2024-06-15"},
+		{name: "month first", s: "06/15/2024", want: "2024-06-15"},
+		{name: "month first with dmy", dateFormat: "dmy", s: "06/15/2024", want: "2024-06-15"},
+		{name: "dotted", dateFormat: "mdy", s: "03.04.2024", want: "2024-04-03"},
+		{name: "invalid date-format", dateFormat: "ymd", s: "03/04/2024", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t, "")
+			oldViper := v
+			t.Cleanup(func() { v = oldViper })
+			v = viper.New()
+			if tt.dateFormat != "" {
+				v.Set("date-format", tt.dateFormat)
+			}
+
+			got, ok, err := parseLocaleDate(tt.s)
+			if !ok {
+				t.Fatalf("parseLocaleDate(%q) didn't match a layout", tt.s)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLocaleDate(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Format(time.DateOnly) != tt.want {
+				t.Errorf("parseLocaleDate(%q) = %s, want %s", tt.s, got.Format(time.DateOnly), tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseHighlightSpec_meridiem(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantStart  int
+		wantMinute int
+		wantSpan   int
+		wantOffset int
+		wantErr    bool
+	}{
+		{spec: "9am+0", wantStart: 9, wantSpan: 1},
+		{spec: "9AM+0", wantStart: 9, wantSpan: 1},
+		{spec: "9pm+0", wantStart: 21, wantSpan: 1},
+		{spec: "9 PM+0", wantStart: 21, wantSpan: 1},
+		{spec: "9 pm@UTC", wantStart: 21, wantSpan: 1},
+		{spec: "9 PM UTC", wantStart: 21, wantSpan: 1},
+		{spec: "12am+0", wantStart: 0, wantSpan: 1},
+		{spec: "12pm+0", wantStart: 12, wantSpan: 1},
+		{spec: "12:30am+0", wantStart: 0, wantMinute: 30, wantSpan: 1},
+		{spec: "3pm+11", wantStart: 4, wantSpan: 1, wantOffset: 11 * 60},
+		{spec: "9:30am-5", wantStart: 14, wantMinute: 30, wantSpan: 1, wantOffset: -5 * 60},
+		{spec: "3pm-5pm+0", wantStart: 15, wantSpan: 2},
+		{spec: "11pm-1am+0", wantStart: 23, wantSpan: 2},
+		{spec: "12am-12pm+0", wantStart: 0, wantSpan: 12},
+		{spec: "15pm+0", wantErr: true},
+		{spec: "13am+0", wantErr: true},
+		{spec: "0am+0", wantErr: true},
+		{spec: "9:60am+0", wantErr: true},
+		{spec: "9xm+0", wantErr: true},
+		{spec: "9pmpm+0", wantErr: true},
+		{spec: "3pm-15pm+0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			start, minute, span, offset, _, err := parseHighlightSpec(tt.spec, "2024-06-15")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHighlightSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if start != tt.wantStart || minute != tt.wantMinute || span != tt.wantSpan || offset != tt.wantOffset {
+				t.Errorf("parseHighlightSpec(%q) = %d, %d, %d, %d, want %d, %d, %d, %d", tt.spec, start, minute, span, offset, tt.wantStart, tt.wantMinute, tt.wantSpan, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func Test_parseHighlightFlag_meridiem(t *testing.T) {
+	zones, err := processTimezones(context.Background(), []string{"UTC", "Australia/Sydney"}, "2024-06-15", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12-hour and 24-hour spellings of the same time highlight the same column
+	for _, pair := range [][2]string{{"3pm+10", "15+10"}, {"12am+0", "0+0"}, {"12pm+0", "12+0"}, {"9 PM+10", "21+10"}} {
+		got, _, _, err := parseHighlightFlag([]string{pair[0]}, zones, "2024-06-15", 0)
+		if err != nil {
+			t.Fatalf("parseHighlightFlag(%q) error = %v", pair[0], err)
+		}
+		want, _, _, err := parseHighlightFlag([]string{pair[1]}, zones, "2024-06-15", 0)
+		if err != nil {
+			t.Fatalf("parseHighlightFlag(%q) error = %v", pair[1], err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("parseHighlightFlag(%q) = %v, want %v as for %q", pair[0], got, want, pair[1])
+		}
+	}
+	if _, _, _, err := parseHighlightFlag([]string{"15pm+10"}, zones, "2024-06-15", 0); err == nil {
+		t.Errorf("parseHighlightFlag(\"15pm+10\") succeeded, want an error")
+	}
+}
+
+func Test_parseAMPMStyle(t *testing.T) {
+	tests := []struct {
+		style   string
+		want    [2]string
+		wantErr bool
+	}{
+		{style: "", want: [2]string{"am", "pm"}},
+		{style: "lower", want: [2]string{"am", "pm"}},
+		{style: "UPPER", want: [2]string{"AM", "PM"}},
+		{style: "single", want: [2]string{"a", "p"}},
+		{style: "vm/nm", want: [2]string{"vm", "nm"}},
+		{style: " a.m. / p.m. ", wantErr: true},
+		{style: "am/", wantErr: true},
+		{style: "ampm", wantErr: true},
+		{style: "午前/午後", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			got, err := parseAMPMStyle(tt.style)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAMPMStyle(%q) error = %v, wantErr %v", tt.style, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseAMPMStyle(%q) = %v, want %v", tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveRelativeDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// New York springs forward at 02:00 on 2024-03-10 and falls back at 02:00 on 2024-11-03. Late in the evening before
+	// spring forward and just after midnight on fall back day, adding 24 hours rather than a day lands on the wrong date.
+	beforeSpringForward := time.Date(2024, 3, 9, 23, 30, 0, 0, newYork)
+	fallBack := time.Date(2024, 11, 3, 0, 30, 0, 0, newYork)
+	tests := []struct {
+		name    string
+		now     time.Time
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{name: "today", now: beforeSpringForward, s: "today", want: "2024-03-09"},
+		{name: "tomorrow across spring forward", now: beforeSpringForward, s: "tomorrow", want: "2024-03-10"},
+		{name: "+1d across spring forward", now: beforeSpringForward, s: "+1d", want: "2024-03-10"},
+		{name: "+2d across spring forward", now: beforeSpringForward, s: "+2d", want: "2024-03-11"},
+		{name: "weekday across spring forward", now: beforeSpringForward, s: "monday", want: "2024-03-11"},
+		{name: "tomorrow on fall back day", now: fallBack, s: "Tomorrow", want: "2024-11-04"},
+		{name: "+1d on fall back day", now: fallBack, s: "+1d", want: "2024-11-04"},
+		{name: "-1d on fall back day", now: fallBack, s: "-1d", want: "2024-11-02"},
+		{name: "yesterday after fall back", now: time.Date(2024, 11, 4, 0, 30, 0, 0, newYork), s: "yesterday", want: "2024-11-03"},
+		{name: "+1w across fall back", now: time.Date(2024, 10, 30, 23, 30, 0, 0, newYork), s: "+1w", want: "2024-11-06"},
+		{name: "+1d across spring forward in London", now: time.Date(2024, 3, 30, 23, 30, 0, 0, london), s: "+1d", want: "2024-03-31"},
+		{name: "weekday of today is a week away", now: beforeSpringForward, s: "sat", want: "2024-03-16"},
+		{name: "date is unchanged", now: beforeSpringForward, s: "2024-06-15", want: "2024-06-15"},
+		{name: "invalid relative date", now: beforeSpringForward, s: "+1xd", wantErr: true},
+		{name: "signed twice", now: beforeSpringForward, s: "+-1d", wantErr: true},
+	}
+	old := timeNow
+	t.Cleanup(func() { timeNow = old })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeNow = func() time.Time { return tt.now }
+			got, err := resolveRelativeDate(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRelativeDate(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRelativeDate(%q) at %s = %q, want %q", tt.s, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inWorkingHours(t *testing.T) {
+	nineToFive := [2]int{9 * 60, 17 * 60}
+	tests := []struct {
+		name        string
+		localMinute int
+		length      int
+		hours       [2]int
+		want        bool
+	}{
+		{name: "starts at the start", localMinute: 9 * 60, length: 60, hours: nineToFive, want: true},
+		{name: "ends at the end", localMinute: 16 * 60, length: 60, hours: nineToFive, want: true},
+		{name: "runs past the end", localMinute: 16*60 + 30, length: 60, hours: nineToFive},
+		{name: "starts before the start", localMinute: 8*60 + 59, length: 1, hours: nineToFive},
+		{name: "last minute", localMinute: 17*60 - 1, length: 1, hours: nineToFive, want: true},
+		{name: "at the end", localMinute: 17 * 60, length: 1, hours: nineToFive},
+		{name: "whole working day", localMinute: 9 * 60, length: 8 * 60, hours: nineToFive, want: true},
+		{name: "until midnight", localMinute: 23 * 60, length: 60, hours: [2]int{18 * 60, 24 * 60}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inWorkingHours(tt.localMinute, tt.length, tt.hours); got != tt.want {
+				t.Errorf("inWorkingHours(%d, %d, %v) = %v, want %v", tt.localMinute, tt.length, tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getZoneInfo_relativeTo(t *testing.T) {
+	oldRelativeTo := relativeTo
+	t.Cleanup(func() { relativeTo = oldRelativeTo })
+
+	relativeTo = "Asia/Tokyo"
+	z, err := getZoneInfo(context.Background(), "Asia/Kolkata", "2024-06-15", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := z.RelativeOffsetMinutes(); got != -3*60-30 {
+		t.Errorf("RelativeOffsetMinutes() = %d, want %d", got, -3*60-30)
+	}
+
+	// a bad --relative-to is an error returned to the caller, not an exit part way through rendering the table
+	relativeTo = "Bogus/Zone"
+	if _, err := SRenderTimeTable(nil, l, []string{"UTC"}, "2024-06-15", false, false, ""); err == nil || !strings.Contains(err.Error(), "Bogus/Zone") {
+		t.Errorf("SRenderTimeTable() with a bad relative-to timezone error = %v, want it named", err)
+	}
+}
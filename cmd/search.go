@@ -0,0 +1,111 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// timezoneMatch is a timezone found by searchTimezones, lower scores are better matches.
+type timezoneMatch struct {
+	name  string
+	score int
+}
+
+// matchScore returns how well a timezone matches a lowercase query, or -1 if it doesn't match. From best to worst, the
+// location is the query, the location starts with the query, the area is the query, the name contains the query.
+// Spaces in the query match underscores, so "new york" matches America/New_York.
+func matchScore(tz, query string) int {
+	name := strings.ToLower(tz)
+	area, location, hasArea := strings.Cut(name, "/")
+	if !hasArea {
+		location = area
+	}
+	switch {
+	case name == query || location == query:
+		return 0
+	case strings.HasPrefix(location, query):
+		return 1
+	case hasArea && area == query:
+		return 2
+	case strings.Contains(name, query):
+		return 3
+	default:
+		return -1
+	}
+}
+
+// searchTimezones returns the timezones whose name matches the query, case-insensitively, sorted from best to worst
+// match and then by name.
+func searchTimezones(query string) []string {
+	query = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(query)), " ", "_")
+	if query == "" {
+		return nil
+	}
+	var matches []timezoneMatch
+	for _, tz := range timezonesAll {
+		if score := matchScore(tz, query); score >= 0 {
+			matches = append(matches, timezoneMatch{name: tz, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find time zones by part of their name",
+	Long: `Find time zones whose name contains the query, ignoring case, and print each with its current abbreviation and UTC
+offset. The best matches are listed first. Spaces in the query match underscores, and an area name like Europe matches
+every time zone in it. Exits with status 1 if nothing matches.
+
+Examples:
+
+  # Find the time zone for Kolkata:
+  $ timeBuddy search kolk
+
+  # Find time zones for a city with a space in its name:
+  $ timeBuddy search "new york"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		matches := searchTimezones(args[0])
+		if len(matches) == 0 {
+			l.Fatal().Str("query", args[0]).Err(fmt.Errorf("no timezones match")).Send()
+		}
+
+		width := 0
+		for _, tz := range matches {
+			width = max(width, len(tz))
+		}
+		now := time.Now()
+		for _, tz := range matches {
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				l.Error().Str("timezone", tz).Err(err).Send()
+				continue
+			}
+			abbreviation, _ := now.In(loc).Zone()
+			fmt.Printf("%-*s  %-6s UTC%s\n", width, tz, abbreviation, now.In(loc).Format("-07:00"))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen      string
+	serveWatchConfig bool
+	// serveMu serializes requests, since the table is built from the same globals that the --date and --time flags
+	// set. It is also held while the config file is reloaded with --watch-config.
+	serveMu sync.Mutex
+)
+
+// tableZone is a row of the time table in the /api/table response.
+type tableZone struct {
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	Offset       string `json:"offset"`
+	Time         string `json:"time"`
+	Hours        []int  `json:"hours"`
+}
+
+// tableResponse is the /api/table response. The columns are the hours of a UTC day, so each zone's Hours holds its
+// local hour in each column. Highlight is the highlighted column, counting from 0, or -1 if none is highlighted.
+type tableResponse struct {
+	Date      string      `json:"date"`
+	Time      string      `json:"time,omitempty"`
+	Highlight int         `json:"highlight"`
+	Zones     []tableZone `json:"zones"`
+}
+
+// nowZone is an entry in the /api/now response.
+type nowZone struct {
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	Time         string `json:"time"`
+}
+
+// servePage is the page served at /, wrapping the HTML rendering of the table.
+var servePage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>timeBuddy</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: right; white-space: pre; }
+td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+{{ . }}
+</body>
+</html>
+`))
+
+// loadServeZones sets the date and time from the request's date and time query parameters, the same way the --date
+// and --time flags do, and returns the details of the timezones given with tz, or those in the config file if there are
+// none. Callers must hold serveMu.
+func loadServeZones(r *http.Request) (timezoneDetails, error) {
+	q := r.URL.Query()
+
+	date, timeOfDay = time.Now().Format(time.DateOnly), ""
+	if d := q.Get("date"); d != "" {
+		resolved, err := resolveRelativeDate(d)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(time.DateOnly, resolved); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", d)
+		}
+		date = resolved
+	}
+	if t := q.Get("time"); t != "" {
+		hour, minute, err := parseTimeString(t)
+		if err != nil {
+			return nil, err
+		}
+		timeOfDay, specifiedHour, specifiedMinute = t, hour, minute
+	}
+
+	tzs := q["tz"]
+	if len(tzs) == 0 {
+		tzs = includeLocalTimezone(v.GetStringSlice("timezone"))
+	}
+	return processTimezones(r.Context(), deduplicateSlice(tzs), date, l)
+}
+
+// watchConfigFile reloads the config file and the table settings read from it whenever the file changes, until the
+// context is done. The config directory is watched rather than the file, since the file is replaced on every write.
+// viper's own WatchConfig isn't used, since it reloads the config without holding serveMu.
+func watchConfigFile(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(getConfigDir()); err != nil {
+		watcher.Close()
+		return err
+	}
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-watcher.Errors:
+				l.Error().Err(err).Send()
+			case event := <-watcher.Events:
+				if filepath.Clean(event.Name) != getConfigPath() || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				serveMu.Lock()
+				if err := v.ReadInConfig(); err != nil {
+					l.Error().Str("configFile", getConfigPath()).Err(err).Send()
+				} else {
+					loadTableSettings()
+					l.Info().Str("configFile", getConfigPath()).Msg("Reloaded config file:")
+				}
+				serveMu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		l.Error().Err(err).Send()
+	}
+}
+
+// serveTable responds with the time table as JSON.
+func serveTable(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	zones, err := loadServeZones(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := tableResponse{Date: date, Time: timeOfDay, Highlight: time.Now().UTC().Hour(), Zones: []tableZone{}}
+	if timeOfDay != "" {
+		resp.Highlight = specifiedTime(date).UTC().Hour()
+	} else if date != time.Now().Format(time.DateOnly) {
+		resp.Highlight = -1
+	}
+	for _, z := range zones {
+		resp.Zones = append(resp.Zones, tableZone{
+			Timezone:     z.name,
+			Abbreviation: z.abbreviation,
+			Offset:       formatSecondsOffset(z.offsetMinutes * 60),
+			Time:         z.currentTime.Format(time.RFC3339),
+			Hours:        z.hours,
+		})
+	}
+	writeJSON(w, resp)
+}
+
+// serveNow responds with the current time in each timezone as JSON. The date and time query parameters are ignored.
+func serveNow(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	q := r.URL.Query()
+	q.Del("date")
+	q.Del("time")
+	r.URL.RawQuery = q.Encode()
+	zones, err := loadServeZones(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := []nowZone{}
+	for _, z := range zones {
+		now = append(now, nowZone{Timezone: z.name, Abbreviation: z.abbreviation, Time: z.currentTime.Format(time.RFC3339)})
+	}
+	writeJSON(w, now)
+}
+
+// serveHTML responds with the time table rendered as an HTML page.
+func serveHTML(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	zones, err := loadServeZones(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the table's cells may hold terminal escape sequences, i.e. dimmed hours outside of working hours
+	html := text.StripEscape(newTimeTable(zones, false).RenderHTML())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// RenderHTML escapes the cell contents
+	if err := servePage.Execute(w, template.HTML(html)); err != nil {
+		l.Error().Err(err).Send()
+	}
+}
+
+// newServeMux returns the handler for the endpoints served by serve.
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveHTML)
+	mux.HandleFunc("/api/table", serveTable)
+	mux.HandleFunc("/api/now", serveNow)
+	return mux
+}
+
+// serveUntilDone serves srv on ln until the context is done, then shuts it down, letting requests in progress finish.
+func serveUntilDone(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the time table over HTTP",
+	Long: `Start an HTTP server that serves the time table, i.e. to embed it in a dashboard. The server stops gracefully on
+SIGINT or SIGTERM. With --watch-config, changes to the config file, like the saved time zones or --twelve-hour, are
+picked up without restarting.
+
+  /           the table as an HTML page
+  /api/table  the table as JSON
+  /api/now    the current time in each time zone as JSON
+
+Each endpoint accepts the query parameters tz, which can be repeated, date, and time. They accept the same values as
+--timezone, --date, and --time. Without tz, the time zones in the config file are used.
+
+Examples:
+
+  # Serve on port 8080:
+  $ timeBuddy serve --listen :8080
+
+  # Get the table for two time zones at 3pm tomorrow:
+  $ curl 'http://localhost:8080/api/table?tz=Europe/London&tz=Asia/Tokyo&date=tomorrow&time=3pm'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		loadTableSettings()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if serveWatchConfig {
+			if err := watchConfigFile(ctx); err != nil {
+				l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+			}
+		}
+		ln, err := net.Listen("tcp", serveListen)
+		if err != nil {
+			l.Fatal().Str("listen", serveListen).Err(err).Send()
+		}
+		l.Info().Str("listen", serveListen).Msg("Serving time table:")
+		srv := &http.Server{Handler: newServeMux(), ReadHeaderTimeout: 10 * time.Second}
+		if err := serveUntilDone(ctx, srv, ln); err != nil {
+			l.Fatal().Str("listen", serveListen).Err(err).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().BoolVar(&serveWatchConfig, "watch-config", false, "reload the config file whenever it changes")
+	serveCmd.Flags().StringVarP(&serveListen, "listen", "l", ":8080", "``address to listen on, i.e. :8080 or 127.0.0.1:8080")
+}
@@ -0,0 +1,289 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// useServeState restores the date and time the serve handlers set from a request, and the config file timezones,
+// after the test.
+func useServeState(t *testing.T) {
+	t.Helper()
+	oldDate, oldTimeOfDay, oldTimezones := date, timeOfDay, v.Get("timezone")
+	oldHour, oldMinute := specifiedHour, specifiedMinute
+	t.Cleanup(func() {
+		date, timeOfDay = oldDate, oldTimeOfDay
+		specifiedHour, specifiedMinute = oldHour, oldMinute
+		v.Set("timezone", oldTimezones)
+	})
+}
+
+// getServe requests the path from the serve endpoints and returns the response's status and body.
+func getServe(t *testing.T, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// specifiedTimeHour returns the UTC hour of the local time hour:00 on the date, the column /api/table highlights for
+// it.
+func specifiedTimeHour(t *testing.T, d string, hour int) int {
+	t.Helper()
+	day, err := time.ParseInLocation(time.DateOnly, d, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day.Add(time.Duration(hour) * time.Hour).UTC().Hour()
+}
+
+func Test_serveTable(t *testing.T) {
+	useServeState(t)
+	srv := httptest.NewServer(newServeMux())
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		query         string
+		wantStatus    int
+		wantZones     []string
+		wantHighlight int
+	}{
+		{
+			name:          "timezones and date",
+			query:         "?tz=UTC&tz=Asia/Tokyo&date=2024-06-15",
+			wantStatus:    http.StatusOK,
+			wantZones:     []string{"UTC", "Asia/Tokyo"},
+			wantHighlight: -1,
+		},
+		{
+			name:          "time is highlighted",
+			query:         "?tz=UTC&date=2024-06-15&time=15:00",
+			wantStatus:    http.StatusOK,
+			wantZones:     []string{"UTC"},
+			wantHighlight: specifiedTimeHour(t, "2024-06-15", 15),
+		},
+		{
+			name:       "invalid timezone",
+			query:      "?tz=Bad/Zone",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid date",
+			query:      "?tz=UTC&date=15/06/2024x",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := getServe(t, srv, "/api/table"+tt.query)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+			if status != http.StatusOK {
+				return
+			}
+			var resp tableResponse
+			if err := json.Unmarshal([]byte(body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var zones []string
+			for _, z := range resp.Zones {
+				zones = append(zones, z.Timezone)
+				if len(z.Hours) != 24 {
+					t.Errorf("%s has %d hours, want 24", z.Timezone, len(z.Hours))
+				}
+			}
+			if strings.Join(zones, ",") != strings.Join(tt.wantZones, ",") {
+				t.Errorf("zones = %v, want %v", zones, tt.wantZones)
+			}
+			if resp.Date != "2024-06-15" || resp.Highlight != tt.wantHighlight {
+				t.Errorf("date = %s, highlight = %d, want 2024-06-15, %d", resp.Date, resp.Highlight, tt.wantHighlight)
+			}
+		})
+	}
+}
+
+func Test_serveNow(t *testing.T) {
+	useServeState(t)
+	srv := httptest.NewServer(newServeMux())
+	defer srv.Close()
+
+	// the timezones in the config file are used without tz, and the date is ignored
+	v.Set("timezone", []string{"Asia/Tokyo"})
+	status, body := getServe(t, srv, "/api/now?date=2024-06-15")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var now []nowZone
+	if err := json.Unmarshal([]byte(body), &now); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, z := range now {
+		found = found || z.Timezone == "Asia/Tokyo"
+		zt, err := time.Parse(time.RFC3339, z.Time)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(zt); d < -time.Minute || d > time.Minute {
+			t.Errorf("%s time = %s, want the current time", z.Timezone, z.Time)
+		}
+	}
+	if !found {
+		t.Errorf("zones = %v, want Asia/Tokyo from the config file", now)
+	}
+}
+
+func Test_serveHTML(t *testing.T) {
+	useServeState(t)
+	srv := httptest.NewServer(newServeMux())
+	defer srv.Close()
+
+	status, body := getServe(t, srv, "/?tz=Europe/London&date=2024-06-15")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", status, http.StatusOK, body)
+	}
+	for _, s := range []string{"<!DOCTYPE html>", "<table", "Europe/London", "Showing Time For: Saturday, June 15, 2024"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("page doesn't contain %q", s)
+		}
+	}
+	if strings.Contains(body, "\x1b[") {
+		t.Errorf("page contains terminal escape sequences")
+	}
+
+	if status, _ := getServe(t, srv, "/missing"); status != http.StatusNotFound {
+		t.Errorf("status of /missing = %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func Test_serveUntilDone(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serveUntilDone(ctx, srv, ln)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+
+	// stop the server while a request is in progress, which is still answered
+	<-started
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("serveUntilDone() returned %v before the request in progress finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	if r := <-responses; r.err != nil || r.body != "done" {
+		t.Errorf("response = %q, %v, want done", r.body, r.err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serveUntilDone() error = %v", err)
+	}
+	if _, err := http.Get("http://" + ln.Addr().String()); err == nil {
+		t.Errorf("server still accepts requests after shutting down")
+	}
+}
+
+func Test_watchConfigFile(t *testing.T) {
+	useServeState(t)
+	path := useTestConfig(t, "timezone:\n  - Asia/Tokyo\n")
+	oldViper := v
+	oldColor, oldTwelveHour, oldWorkingHours, oldMarkers := colorEnabled, twelveHourEnabled, workingHours, ampmMarkers
+	t.Cleanup(func() {
+		v = oldViper
+		colorEnabled, twelveHourEnabled, workingHours, ampmMarkers = oldColor, oldTwelveHour, oldWorkingHours, oldMarkers
+	})
+	v = viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(configType)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	loadTableSettings()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watchConfigFile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newServeMux())
+	defer srv.Close()
+
+	if _, body := getServe(t, srv, "/api/now"); !strings.Contains(body, "Asia/Tokyo") {
+		t.Fatalf("/api/now before the config file changed = %s, want Asia/Tokyo", body)
+	}
+	if _, body := getServe(t, srv, "/?date=2024-06-15"); strings.Contains(body, "pm") {
+		t.Fatalf("page before the config file changed is in 12-hour format")
+	}
+
+	// the file is replaced, as atomicWriteConfig does
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("timezone:\n  - Europe/Berlin\ntwelve-hour: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, now := getServe(t, srv, "/api/now")
+		_, page := getServe(t, srv, "/?date=2024-06-15")
+		if strings.Contains(now, "Europe/Berlin") && !strings.Contains(now, "Asia/Tokyo") && strings.Contains(page, "pm") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("output didn't change after the config file changed:\n%s\n%s", now, page)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
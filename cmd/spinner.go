@@ -0,0 +1,64 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	// spinnerDelay is how long to wait before showing the spinner, so it doesn't flicker when loading is fast
+	spinnerDelay = 250 * time.Millisecond
+	// spinnerInterval is how often the spinner advances
+	spinnerInterval = 100 * time.Millisecond
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerEnabled reports whether to show a spinner while loading. It is only shown above a table written to a terminal,
+// since scripts reading --format output don't want animated output, and never with --quiet.
+func spinnerEnabled() bool {
+	if quietEnabled || (format != "" && format != "unix") {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+}
+
+// withSpinner runs fn, showing a spinner followed by the label on stderr if it takes longer than spinnerDelay. The
+// spinner's line is cleared once fn returns.
+func withSpinner(label string, fn func() error) error {
+	if !spinnerEnabled() {
+		return fn()
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-done:
+			return
+		case <-time.After(spinnerDelay):
+		}
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], label)
+			select {
+			case <-done:
+				fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", len(label)+2))
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	err := fn()
+	close(done)
+	<-stopped
+	return err
+}
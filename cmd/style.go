@@ -0,0 +1,165 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/viper"
+)
+
+// fill characters prefixed to hours with --hour-bands when color is disabled
+const (
+	bandWork  = "█"
+	bandAwake = "▒"
+	bandSleep = "░"
+)
+
+// default backgrounds for --hour-bands. Each is shown with white text, so they read the same on dark and light
+// terminals.
+const (
+	defaultWorkBg  = "28"
+	defaultAwakeBg = "60"
+	defaultSleepBg = "236"
+)
+
+var (
+	hourBandsEnabled bool
+	bandColors       hourColors
+	rowColorFlag     []string
+	// rowColors are the text colors of rows, keyed by lowercase timezone name, from row_colors and --row-color
+	rowColors map[string]text.Colors
+)
+
+// hourColors are the backgrounds used by --hour-bands, read from the style.working_hours_color, style.awake_color, and
+// style.sleep_color config keys.
+type hourColors struct {
+	work  text.Colors
+	awake text.Colors
+	sleep text.Colors
+}
+
+// hourBand is the part of the day an hour falls in for --hour-bands: working hours, awake outside of working hours,
+// 06:00-22:59, or sleeping, 23:00-05:59.
+type hourBand int
+
+const (
+	bandSleeping hourBand = iota
+	bandAwakeOff
+	bandWorking
+)
+
+// bandForHour returns the part of the day a local hour falls in. workStart and workEnd are in minutes since local
+// midnight, like the working hours of a timezoneDetail.
+func bandForHour(localHour, workStart, workEnd int) hourBand {
+	switch minute := localHour * 60; {
+	case minute >= workStart && minute < workEnd:
+		return bandWorking
+	case localHour >= 6 && localHour <= 22:
+		return bandAwakeOff
+	default:
+		return bandSleeping
+	}
+}
+
+// parseHourColors parses the working hours, awake, and sleep background colors. Empty values use the defaults. Each
+// color is shown with white text.
+func parseHourColors(work, awake, sleep string) (hourColors, error) {
+	if work == "" {
+		work = defaultWorkBg
+	}
+	if awake == "" {
+		awake = defaultAwakeBg
+	}
+	if sleep == "" {
+		sleep = defaultSleepBg
+	}
+	var colors hourColors
+	var err error
+	if colors.work, err = parseBackgroundColor(work); err != nil {
+		return colors, fmt.Errorf("working hours color: %w", err)
+	}
+	if colors.awake, err = parseBackgroundColor(awake); err != nil {
+		return colors, fmt.Errorf("awake color: %w", err)
+	}
+	if colors.sleep, err = parseBackgroundColor(sleep); err != nil {
+		return colors, fmt.Errorf("sleep color: %w", err)
+	}
+	colors.work = append(colors.work, text.FgHiWhite)
+	colors.awake = append(colors.awake, text.FgHiWhite)
+	colors.sleep = append(colors.sleep, text.FgHiWhite)
+	return colors, nil
+}
+
+// cellStyle returns the colors of a cell showing the given local hour with --hour-bands. workStart and workEnd are in
+// minutes since local midnight.
+func cellStyle(localHour int, workStart, workEnd int, colors hourColors) text.Colors {
+	switch bandForHour(localHour, workStart, workEnd) {
+	case bandWorking:
+		return colors.work
+	case bandAwakeOff:
+		return colors.awake
+	default:
+		return colors.sleep
+	}
+}
+
+// bandFillForHour returns the fill character prefixed to an hour with --hour-bands when color is disabled, █ for
+// working hours, ▒ for awake, and ░ for sleeping.
+func bandFillForHour(localHour, workStart, workEnd int) string {
+	switch bandForHour(localHour, workStart, workEnd) {
+	case bandWorking:
+		return bandWork
+	case bandAwakeOff:
+		return bandAwake
+	default:
+		return bandSleep
+	}
+}
+
+// getRowColors returns the text color of each timezone's row, keyed by lowercase timezone name. It reads the row_colors
+// map from the config file and applies any overrides, which are in the format timezone=color. Colors are a color code
+// from 0 to 255 or #RRGGBB. Viper lowercases map keys, so the timezone names are lowercased to match.
+func getRowColors(v *viper.Viper, overrides []string) (map[string]text.Colors, error) {
+	colors := make(map[string]text.Colors)
+	add := func(tz, color string) error {
+		c, err := parseForegroundColor(color)
+		if err != nil {
+			return err
+		}
+		if canonical, ok := matchTimezoneName(tz); ok {
+			tz = canonical
+		}
+		colors[strings.ToLower(strings.TrimSpace(tz))] = c
+		return nil
+	}
+	for tz, color := range v.GetStringMapString("row_colors") {
+		if err := add(tz, color); err != nil {
+			return nil, fmt.Errorf("row_colors for %s: %w", tz, err)
+		}
+	}
+	for _, val := range overrides {
+		tz, color, ok := strings.Cut(val, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid row color %q, expected a format like America/New_York=#FF0000", val)
+		}
+		if err := add(tz, color); err != nil {
+			return nil, err
+		}
+	}
+	return colors, nil
+}
+
+// rowStyle returns the colors of a cell in the timezone's row, the background of the cell followed by the row's text
+// color, if it has one, so the row color composes with --shade and --hour-bands. The background is copied, so it
+// isn't changed.
+func rowStyle(z timezoneDetail, background text.Colors) text.Colors {
+	fg, ok := rowColors[strings.ToLower(z.name)]
+	if !ok || !colorEnabled {
+		return background
+	}
+	return append(append(text.Colors{}, background...), fg...)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestDate         string
+	suggestDuration     int
+	suggestTimezones    []string
+	suggestWorkingHours []string
+)
+
+// outOfHoursZones returns the zones for which a meeting starting at t and lasting the given duration doesn't fit
+// entirely inside their working hours.
+func outOfHoursZones(t time.Time, duration time.Duration, zones timezoneDetails, defaultHours [2]int) []string {
+	var out []string
+	for _, z := range zones {
+		loc, err := loadLocation(z.name)
+		if err != nil {
+			out = append(out, z.name)
+			continue
+		}
+		lt := t.In(loc)
+		if !inWorkingHours(lt.Hour()*60+lt.Minute(), int(duration.Minutes()), zoneWorkingHours(z, defaultHours)) {
+			out = append(out, z.name)
+		}
+	}
+	return out
+}
+
+// suggestMeetingWindows returns the UTC hours of the given day at which a meeting of the given duration fits inside
+// the working hours of every zone. Zones without their own working hours use the default hours. The returned hours are
+// sorted.
+func suggestMeetingWindows(zones timezoneDetails, day time.Time, duration time.Duration, defaultHours [2]int) []int {
+	var hours []int
+	for h := 0; h < 24; h++ {
+		t := day.Add(time.Duration(h) * time.Hour)
+		if len(outOfHoursZones(t, duration, zones, defaultHours)) == 0 {
+			hours = append(hours, h)
+		}
+	}
+	return hours
+}
+
+// leastBadMeetingWindows returns the UTC hours of the given day with the fewest zones outside working hours, along
+// with how many zones are outside working hours at those times. It is used when suggestMeetingWindows finds nothing.
+func leastBadMeetingWindows(zones timezoneDetails, day time.Time, duration time.Duration, defaultHours [2]int) ([]int, int) {
+	var hours []int
+	fewest := len(zones) + 1
+	for h := 0; h < 24; h++ {
+		t := day.Add(time.Duration(h) * time.Hour)
+		n := len(outOfHoursZones(t, duration, zones, defaultHours))
+		if n < fewest {
+			fewest = n
+			hours = nil
+		}
+		if n == fewest {
+			hours = append(hours, h)
+		}
+	}
+	return hours, fewest
+}
+
+// printSuggestions prints a table with a row for each suggested UTC hour showing the local time in every zone.
+func printSuggestions(title string, hours []int, day time.Time, zones timezoneDetails) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.Style().Title.Align = text.AlignCenter
+	t.Style().Format.Header = text.FormatDefault
+	t.SetTitle(title)
+
+	header := table.Row{"UTC"}
+	for _, z := range zones {
+		header = append(header, z.name)
+	}
+	t.AppendHeader(header)
+
+	for _, h := range hours {
+		ut := day.Add(time.Duration(h) * time.Hour)
+		row := table.Row{ut.Format("15:04")}
+		for _, z := range zones {
+			loc, _ := loadLocation(z.name)
+			row = append(row, ut.In(loc).Format("Mon 15:04"))
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+}
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest meeting times that fall within everyone's working hours",
+	Long: `Suggest the UTC hours at which a meeting fits inside the working hours of every time zone, along with the local time
+each suggestion corresponds to in every time zone.
+
+Working hours default to 09:00-17:00, or the working_hours set for a time zone in the config file. Use --working-hours
+08:30-16:30 to change the default for all time zones, or --working-hours Asia/Singapore=10:00-19:00 to change them for a
+single time zone. If no hour works for everyone, the hours with the fewest time zones outside working hours are shown
+instead.
+
+Examples:
+
+  # Suggest meeting times for the time zones in the config file:
+  $ timeBuddy suggest
+
+  # Suggest times for a 90 minute meeting on a specific date, with custom working hours:
+  $ timeBuddy suggest --timezone America/New_York --timezone Europe/Berlin --timezone Asia/Singapore \
+      --working-hours 09:00-18:00 --date 2024-11-05 --duration 90`,
+	Run: func(cmd *cobra.Command, args []string) {
+		suggestTimezones = deduplicateSlice(suggestTimezones)
+		if len(suggestTimezones) == 0 {
+			l.Fatal().Err(fmt.Errorf("no timezones configured, use --timezone to specify one")).Send()
+		}
+		if suggestDuration <= 0 || suggestDuration > 24*60 {
+			l.Fatal().Int("duration", suggestDuration).Err(fmt.Errorf("duration must be between 1 and 1440 minutes")).Send()
+		}
+
+		defaultHours, wh, err := loadWorkingHoursFlag(suggestWorkingHours)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		// getZoneInfo marks the zones that have their own working hours
+		workingHours = wh
+
+		day := time.Now().UTC().Format(time.DateOnly)
+		if cmd.Flags().Changed("date") {
+			resolved, err := resolveRelativeDate(suggestDate)
+			if err != nil {
+				l.Fatal().Str("date", suggestDate).Err(err).Send()
+			}
+			if _, err := time.Parse(time.DateOnly, resolved); err != nil {
+				l.Fatal().Str("date", suggestDate).Err(err).Send()
+			}
+			day = resolved
+		}
+		start, _ := time.Parse(time.DateOnly, day)
+
+		var zones timezoneDetails
+		for _, tz := range suggestTimezones {
+			z, err := getZoneInfo(cmd.Context(), tz, day, l)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			zones = append(zones, z)
+		}
+
+		duration := time.Duration(suggestDuration) * time.Minute
+		if hours := suggestMeetingWindows(zones, start, duration, defaultHours); len(hours) > 0 {
+			printSuggestions(fmt.Sprintf("Suggested Meeting Times: %s", day), hours, start, zones)
+			return
+		}
+
+		hours, out := leastBadMeetingWindows(zones, start, duration, defaultHours)
+		// the count is printed above the table rather than in its title, which would be wider than a table of two zones
+		fmt.Printf("No meeting time fits within everyone's working hours on %s. These leave %d of %d zones out of hours.\n", day, out, len(zones))
+		printSuggestions(fmt.Sprintf("Least Bad Meeting Times: %s", day), hours, start, zones)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+	suggestCmd.Flags().StringVarP(&suggestDate, "date", "d", "", "``date to suggest meeting times for. Accepts the same values as timeBuddy --date. Defaults to today.")
+	suggestCmd.Flags().IntVar(&suggestDuration, "duration", 60, "``length of the meeting in minutes. The whole meeting must fit within working hours.")
+	suggestCmd.Flags().StringArrayVarP(&suggestTimezones, "timezone", "z", []string{}, "``timezone of a participant. Can be used multiple times. Defaults to the timezones in the config file.")
+	suggestCmd.Flags().StringArrayVarP(&suggestWorkingHours, "working-hours", "w", []string{}, "``working hours as HH:MM-HH:MM, or timezone=HH:MM-HH:MM to override a single timezone. Can be used multiple times.")
+	err := suggestCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// clock24Pattern matches 24-hour times, i.e. 15:00 or 9:30
+	clock24Pattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	// clock12Pattern matches 12-hour times with an am/pm suffix, i.e. 3pm, 3:30pm, 11 a.m., or 9a
+	clock12Pattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*([ap])\.?(?:m\.?)?$`)
+)
+
+// parseTimeString parses a time of day and returns its hour and minute. It accepts 24-hour times like 15:00, 12-hour
+// times like 3pm or 3:30pm, and the words noon, midnight, and now, which is the current local time.
+func parseTimeString(s string) (hour, minute int, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	case "now":
+		now := timeNow()
+		return now.Hour(), now.Minute(), nil
+	}
+
+	if m := clock24Pattern.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0, 0, fmt.Errorf("invalid time %q, hour must be 0-23 and minute 0-59", s)
+		}
+		return hour, minute, nil
+	}
+
+	if m := clock12Pattern.FindStringSubmatch(s); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, fmt.Errorf("invalid time %q, hour must be 1-12 and minute 0-59", s)
+		}
+		// 12am is midnight and 12pm is noon
+		hour %= 12
+		if m[3] == "p" {
+			hour += 12
+		}
+		return hour, minute, nil
+	}
+
+	return 0, 0, fmt.Errorf("invalid time %q, expected a time like 15:00, 3pm, 3:30pm, noon, midnight, or now", s)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_parseTimeString(t *testing.T) {
+	old := timeNow
+	timeNow = func() time.Time { return time.Date(2024, 6, 15, 14, 42, 0, 0, time.Local) }
+	t.Cleanup(func() { timeNow = old })
+
+	tests := []struct {
+		s          string
+		wantHour   int
+		wantMinute int
+		wantErr    bool
+	}{
+		{s: "15:00", wantHour: 15},
+		{s: "9:30", wantHour: 9, wantMinute: 30},
+		{s: "00:00", wantHour: 0},
+		{s: "23:59", wantHour: 23, wantMinute: 59},
+		{s: "3pm", wantHour: 15},
+		{s: "3:30pm", wantHour: 15, wantMinute: 30},
+		{s: "3 PM", wantHour: 15},
+		{s: "11 a.m.", wantHour: 11},
+		{s: "9a", wantHour: 9},
+		{s: "12am", wantHour: 0},
+		{s: "12pm", wantHour: 12},
+		{s: "noon", wantHour: 12},
+		{s: "Midnight", wantHour: 0},
+		{s: "now", wantHour: 14, wantMinute: 42},
+		{s: "24:00", wantErr: true},
+		{s: "12:60", wantErr: true},
+		{s: "0am", wantErr: true},
+		{s: "13pm", wantErr: true},
+		{s: "3:75pm", wantErr: true},
+		{s: "15", wantErr: true},
+		{s: "teatime", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			hour, minute, err := parseTimeString(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeString(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && (hour != tt.wantHour || minute != tt.wantMinute) {
+				t.Errorf("parseTimeString(%q) = %d:%02d, want %d:%02d", tt.s, hour, minute, tt.wantHour, tt.wantMinute)
+			}
+		})
+	}
+}
+
+func Test_parseTimeString_roundTrip(t *testing.T) {
+	// every 12-hour time parses to the same hour and minute as its 24-hour form
+	for hour := 0; hour < 24; hour++ {
+		for _, minute := range []int{0, 30, 59} {
+			h12, suffix := hour%12, "am"
+			if h12 == 0 {
+				h12 = 12
+			}
+			if hour >= 12 {
+				suffix = "pm"
+			}
+			clock24 := time.Date(2024, 6, 15, hour, minute, 0, 0, time.UTC).Format("15:04")
+			clock12 := time.Date(2024, 6, 15, hour, minute, 0, 0, time.UTC).Format("3:04") + suffix
+			h24, m24, err := parseTimeString(clock24)
+			if err != nil {
+				t.Fatalf("parseTimeString(%q) error = %v", clock24, err)
+			}
+			h, m, err := parseTimeString(clock12)
+			if err != nil {
+				t.Fatalf("parseTimeString(%q) error = %v", clock12, err)
+			}
+			if h != h24 || m != m24 || h != hour || m != minute {
+				t.Errorf("parseTimeString(%q) = %d:%02d, parseTimeString(%q) = %d:%02d, want both %d:%02d", clock12, h, m, clock24, h24, m24, hour, minute)
+			}
+			if minute == 0 {
+				if h, m, err := parseTimeString(fmt.Sprintf("%d%s", h12, suffix)); err != nil || h != hour || m != 0 {
+					t.Errorf("parseTimeString(\"%d%s\") = %d:%02d, %v, want %d:00", h12, suffix, h, m, err, hour)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"regexp"
+	"sync"
+	"time"
+)
+
+// cachedZoneInfo is a loaded timezone and the abbreviation and offset it uses from validFrom until validUntil, the
+// surrounding offset transitions. A zero validFrom or validUntil means there is no transition in that direction.
+type cachedZoneInfo struct {
+	loc          *time.Location
+	abbreviation string
+	offset       int
+	validFrom    time.Time
+	validUntil   time.Time
+}
+
+var (
+	noCache bool
+	// tzCache is keyed by timezone name. It is shared by the goroutines started by processTimezones.
+	tzCache   = map[string]cachedZoneInfo{}
+	tzCacheMu sync.Mutex
+)
+
+// offsetZonePattern matches a UTC offset used as a timezone, i.e. UTC+7, GMT-3, +05:30, or -8
+var offsetZonePattern = regexp.MustCompile(`^(?i:UTC|GMT)?([+-]\d{1,2}(?::?\d{2})?)$`)
+
+// offsetZone returns a fixed zone for a UTC offset used as a timezone, named like UTC+7 or UTC+5:30, and reports whether
+// the timezone is an offset at all.
+func offsetZone(timezone string) (*time.Location, bool, error) {
+	m := offsetZonePattern.FindStringSubmatch(timezone)
+	if m == nil {
+		return nil, false, nil
+	}
+	minutes, err := parseOffset(m[1], "")
+	if err != nil {
+		return nil, true, err
+	}
+	return time.FixedZone("UTC"+formatHighlightOffset(minutes), minutes*60), true, nil
+}
+
+// covers reports whether the cached abbreviation and offset apply at t.
+func (c cachedZoneInfo) covers(t time.Time) bool {
+	return (c.validFrom.IsZero() || !t.Before(c.validFrom)) && (c.validUntil.IsZero() || t.Before(c.validUntil))
+}
+
+// loadLocation returns the location for a timezone, loading it from tzdata only the first time unless --no-cache is
+// set. A UTC offset like UTC+7 or +05:30 is a fixed zone at that offset.
+func loadLocation(timezone string) (*time.Location, error) {
+	if !noCache {
+		tzCacheMu.Lock()
+		c, ok := tzCache[timezone]
+		tzCacheMu.Unlock()
+		if ok {
+			return c.loc, nil
+		}
+	}
+	loc, isOffset, err := offsetZone(timezone)
+	if !isOffset {
+		loc, err = time.LoadLocation(timezone)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !noCache {
+		tzCacheMu.Lock()
+		if _, ok := tzCache[timezone]; !ok {
+			tzCache[timezone] = cachedZoneInfo{loc: loc}
+		}
+		tzCacheMu.Unlock()
+	}
+	return loc, nil
+}
+
+// zoneAt returns the abbreviation and offset, in seconds east of UTC, used by the timezone at t. The result is cached
+// until the timezone's next offset transition, so repeated lookups between transitions skip the zone lookup.
+func zoneAt(timezone string, loc *time.Location, t time.Time) (abbreviation string, offset int) {
+	if !noCache {
+		tzCacheMu.Lock()
+		c, ok := tzCache[timezone]
+		tzCacheMu.Unlock()
+		if ok && c.abbreviation != "" && c.covers(t) {
+			return c.abbreviation, c.offset
+		}
+	}
+	local := t.In(loc)
+	abbreviation, offset = local.Zone()
+	if !noCache {
+		start, end := local.ZoneBounds()
+		tzCacheMu.Lock()
+		tzCache[timezone] = cachedZoneInfo{loc: loc, abbreviation: abbreviation, offset: offset, validFrom: start, validUntil: end}
+		tzCacheMu.Unlock()
+	}
+	return abbreviation, offset
+}
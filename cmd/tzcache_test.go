@@ -0,0 +1,95 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// useEmptyTZCache empties the timezone cache, and again after the test.
+func useEmptyTZCache(t *testing.T) {
+	t.Helper()
+	empty := func() {
+		tzCacheMu.Lock()
+		tzCache = map[string]cachedZoneInfo{}
+		tzCacheMu.Unlock()
+	}
+	empty()
+	oldNoCache := noCache
+	t.Cleanup(func() {
+		noCache = oldNoCache
+		empty()
+	})
+}
+
+func Test_tzCache(t *testing.T) {
+	tzs := []string{"America/New_York", "Europe/London", "Asia/Kolkata", "Australia/Lord_Howe", "UTC+5:45"}
+	// the cache is filled on the first date, so the second and third dates are looked up after a DST change from it
+	dates := []string{"2024-06-15", "2024-12-15", "2024-03-10"}
+
+	render := func(d string) string {
+		zones, err := processTimezones(context.Background(), tzs, d, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return SprintTimeTable(zones, false, -1, false, d)
+	}
+
+	useEmptyTZCache(t)
+	noCache = true
+	want := make([]string, len(dates))
+	for i, d := range dates {
+		want[i] = render(d)
+	}
+
+	noCache = false
+	for _, pass := range []string{"miss", "hit"} {
+		for i, d := range dates {
+			if got := render(d); got != want[i] {
+				t.Errorf("table on %s with a cache %s =\n%s\nwant\n%s", d, pass, got, want[i])
+			}
+		}
+	}
+	tzCacheMu.Lock()
+	cached := len(tzCache)
+	// the relative-to timezone is looked up with every timezone, for the relative offset
+	if _, ok := tzCache[relativeTo]; ok && !slices.Contains(tzs, relativeTo) {
+		cached--
+	}
+	tzCacheMu.Unlock()
+	if cached != len(tzs) {
+		t.Errorf("%d timezones cached, want %d", cached, len(tzs))
+	}
+}
+
+func Test_zoneAt(t *testing.T) {
+	useEmptyTZCache(t)
+	loc, err := loadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name             string
+		t                time.Time
+		wantAbbreviation string
+		wantOffset       int
+	}{
+		{name: "miss", t: time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC), wantAbbreviation: "EDT", wantOffset: -4 * 3600},
+		{name: "hit", t: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC), wantAbbreviation: "EDT", wantOffset: -4 * 3600},
+		{name: "after the cached offset ends", t: time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC), wantAbbreviation: "EST", wantOffset: -5 * 3600},
+		{name: "before the cached offset starts", t: time.Date(2024, 3, 10, 6, 59, 0, 0, time.UTC), wantAbbreviation: "EST", wantOffset: -5 * 3600},
+		{name: "hit after a miss", t: time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC), wantAbbreviation: "EDT", wantOffset: -4 * 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			abbreviation, offset := zoneAt("America/New_York", loc, tt.t)
+			if abbreviation != tt.wantAbbreviation || offset != tt.wantOffset {
+				t.Errorf("zoneAt() = %s, %d, want %s, %d", abbreviation, offset, tt.wantAbbreviation, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	untilLive     bool
+	untilOutput   string
+	untilTimezone string
+)
+
+// countdown describes the time remaining until a target instant.
+type countdown struct {
+	Target           string `json:"target"`
+	TargetLocal      string `json:"target_local"`
+	Now              string `json:"now"`
+	RemainingSeconds int64  `json:"remaining_seconds"`
+	Remaining        string `json:"remaining"`
+}
+
+// formatCountdown formats a duration as days, hours, and minutes, i.e. "3d 4h 17m". Durations under a minute include
+// seconds so the live countdown keeps moving. Negative durations are prefixed with a minus sign.
+func formatCountdown(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Truncate(time.Second)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%s%dd %dh %dm", sign, days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%s%dh %dm", sign, hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%s%dm %ds", sign, minutes, seconds)
+	default:
+		return fmt.Sprintf("%s%ds", sign, seconds)
+	}
+}
+
+// getCountdown returns the countdown from now until the target.
+func getCountdown(target, now time.Time) countdown {
+	remaining := target.Sub(now)
+	return countdown{
+		Target:           target.Format(time.RFC3339),
+		TargetLocal:      target.Local().Format(time.RFC3339),
+		Now:              now.Format(time.RFC3339),
+		RemainingSeconds: int64(remaining.Seconds()),
+		Remaining:        formatCountdown(remaining),
+	}
+}
+
+// formatCountdownLine returns a one line description of the countdown, using "ago" for instants in the past.
+func formatCountdownLine(target time.Time, c countdown) string {
+	local := target.Local().Format("Monday, Jan 2 3:04PM MST")
+	if c.RemainingSeconds < 0 {
+		return fmt.Sprintf("%s ago (%s local time)", formatCountdown(-time.Duration(c.RemainingSeconds)*time.Second), local)
+	}
+	return fmt.Sprintf("%s remaining (%s local time)", c.Remaining, local)
+}
+
+var untilCmd = &cobra.Command{
+	Use:   "until <time>",
+	Short: "Count down to a time in a time zone",
+	Long: `Print how long remains until a date and time in a time zone, along with the equivalent local time. Times in the past
+print how long ago they were.
+
+The time must be in the format 'YYYY-MM-DD HH:MM' or 'HH:MM', in which case today's date is implied. The time zone
+defaults to your local time zone.
+
+Examples:
+
+  # Count down to 5pm on January 15th in London:
+  $ timeBuddy until "2025-01-15 17:00" --timezone Europe/London
+
+  # Keep counting down to 9am local time, refreshing every second:
+  $ timeBuddy until 09:00 --live
+
+  # Output the countdown as JSON:
+  $ timeBuddy until "2025-01-15 17:00" --timezone Europe/London --output json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		loc, err := loadLocation(resolveAlias(untilTimezone))
+		if err != nil {
+			l.Fatal().Str("timezone", untilTimezone).Err(err).Send()
+		}
+		target, err := parseDateTime(args[0], loc)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+
+		switch untilOutput {
+		case "json":
+			if untilLive {
+				l.Fatal().Err(fmt.Errorf("--live can't be used with json output")).Send()
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(getCountdown(target, time.Now())); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			if !untilLive {
+				fmt.Println(formatCountdownLine(target, getCountdown(target, time.Now())))
+				return
+			}
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				// redraw the countdown in place, clearing the rest of the line in case it got shorter
+				fmt.Printf("\r%s\033[K", formatCountdownLine(target, getCountdown(target, time.Now())))
+				<-ticker.C
+			}
+		default:
+			l.Fatal().Str("output", untilOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(untilCmd)
+	untilCmd.Flags().BoolVarP(&untilLive, "live", "l", false, "refresh the countdown every second until interrupted")
+	untilCmd.Flags().StringVarP(&untilOutput, "output", "o", "text", "``output format, text or json")
+	untilCmd.Flags().StringVarP(&untilTimezone, "timezone", "z", "Local", "``timezone the time is in. Defaults to the local timezone.")
+	if err := untilCmd.Flags().SetAnnotation("timezone", skipConfigAnnotation, []string{"true"}); err != nil {
+		l.Error().Err(err).Send()
+	}
+	err := untilCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
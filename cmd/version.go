@@ -0,0 +1,54 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/JakeTRogers/timeBuddy/internal/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+var versionOutput string
+
+// formatVersion returns the build metadata as the lines printed by the version subcommand and --version.
+func formatVersion(info buildinfo.Info) string {
+	return fmt.Sprintf("timeBuddy %s\n  commit:  %s\n  built:   %s\n  go:      %s\n  tzdata:  %s\n",
+		info.Version, info.Commit, info.Date, info.GoVersion, info.TZDataVersion)
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Long: `Print the version of timeBuddy along with the git commit and date it was built from, the Go version it was built
+with, and the version of the timezone database in use.
+
+Examples:
+
+  # Print the version as JSON:
+  $ timeBuddy version --output json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		info := buildinfo.Get()
+		switch versionOutput {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(info); err != nil {
+				l.Fatal().Err(err).Send()
+			}
+		case "text":
+			fmt.Print(formatVersion(info))
+		default:
+			l.Fatal().Str("output", versionOutput).Err(fmt.Errorf("invalid output format, expected text or json")).Send()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", "text", "``output format, text or json")
+}
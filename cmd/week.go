@@ -0,0 +1,116 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+)
+
+var (
+	weekStart     string
+	weekTime      string
+	weekTimezones []string
+)
+
+// weekOffsetChanges returns a description of each zone whose UTC offset differs from its offset on the first day of
+// the week, i.e. "America/New_York UTC-5 → UTC-4". The zones on both days must be in the same order.
+func weekOffsetChanges(first, day timezoneDetails) []string {
+	var changes []string
+	for i, z := range day {
+		if i < len(first) && z.offsetMinutes != first[i].offsetMinutes {
+			changes = append(changes, fmt.Sprintf("%s UTC%s → UTC%s", z.name, formatOffset(first[i]), formatOffset(z)))
+		}
+	}
+	return changes
+}
+
+var weekCmd = &cobra.Command{
+	Use:   "week",
+	Short: "Show a table for each day of a week",
+	Long: `Show the time table for each of seven days, starting today or on the date given with --start, for the configured
+time zones. Days where a time zone's UTC offset differs from the first day, i.e. after a daylight saving time change, are
+flagged above their table.
+
+Examples:
+
+  # Show the coming week:
+  $ timeBuddy week
+
+  # Show the week of a daylight saving time change, highlighting 9am local time on each day:
+  $ timeBuddy week --start 2025-03-24 --time 9am --timezone America/New_York --timezone Europe/London`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now().Format(time.DateOnly)
+		if cmd.Flags().Changed("start") {
+			resolved, err := resolveRelativeDate(weekStart)
+			if err != nil {
+				l.Fatal().Str("start", weekStart).Err(err).Send()
+			}
+			start = resolved
+		}
+		startDate, err := time.Parse(time.DateOnly, start)
+		if err != nil {
+			l.Fatal().Str("start", start).Err(err).Send()
+		}
+
+		if cmd.Flags().Changed("time") {
+			specifiedHour, specifiedMinute, err = parseTimeString(weekTime)
+			if err != nil {
+				l.Fatal().Str("time", weekTime).Err(err).Send()
+			}
+			timeOfDay = weekTime
+		}
+
+		wh, err := loadWorkingHours(nil)
+		if err != nil {
+			l.Fatal().Err(err).Send()
+		}
+		workingHours = wh
+		markers, err := parseAMPMStyle(v.GetString("ampm-style"))
+		if err != nil {
+			l.Fatal().Str("ampm-style", v.GetString("ampm-style")).Err(err).Send()
+		}
+		ampmMarkers = markers
+
+		targets := deduplicateSlice(weekTimezones)
+		if len(targets) == 0 {
+			targets = []string{"Local"}
+		}
+
+		var first timezoneDetails
+		for i := 0; i < 7; i++ {
+			// printTimeTable and getZoneInfo read the date from the global set by --date
+			date = startDate.AddDate(0, 0, i).Format(time.DateOnly)
+			zones, err := processTimezones(cmd.Context(), targets, date, l)
+			if err != nil {
+				l.Fatal().Err(err).Send()
+			}
+			if i == 0 {
+				first = zones
+			} else if changes := weekOffsetChanges(first, zones); len(changes) > 0 {
+				fmt.Println(text.Colors{text.FgHiYellow, text.Bold}.Sprintf("Offset change: %s", strings.Join(changes, ", ")))
+			}
+			printTimeTable(os.Stdout, zones, colorEnabled)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(weekCmd)
+	weekCmd.Flags().BoolVarP(&colorEnabled, "color", "c", false, "enable colorized table output")
+	weekCmd.Flags().StringVarP(&weekStart, "start", "s", "", "``first day of the week. Accepts the same values as timeBuddy --date. Defaults to today.")
+	weekCmd.Flags().StringVarP(&weekTime, "time", "T", "", "``time of day to highlight on every day, in your local timezone. Accepts the same values as timeBuddy --time.")
+	weekCmd.Flags().BoolVarP(&twelveHourEnabled, "twelve-hour", "t", false, "use 12-hour time format instead of 24-hour")
+	weekCmd.Flags().StringArrayVarP(&weekTimezones, "timezone", "z", []string{}, "``timezone to show. Can be used multiple times. Defaults to the timezones in the config file.")
+	err := weekCmd.RegisterFlagCompletionFunc("timezone", completeTimezone)
+	if err != nil {
+		l.Error().Err(err).Send()
+	}
+}
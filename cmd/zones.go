@@ -0,0 +1,120 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// saveConfiguredTimezones writes the list of timezones to the config file and prints it.
+func saveConfiguredTimezones(tzs []string) {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	fv.Set("timezone", tzs)
+	if err := atomicWriteConfig(fv, getConfigPath()); err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	printConfiguredTimezones(tzs)
+}
+
+// printConfiguredTimezones prints the timezones one per line, or a note if there are none.
+func printConfiguredTimezones(tzs []string) {
+	if len(tzs) == 0 {
+		fmt.Println("No timezones configured.")
+		return
+	}
+	for _, tz := range tzs {
+		fmt.Println(tz)
+	}
+}
+
+// configuredTimezones returns the timezones saved in the config file.
+func configuredTimezones() []string {
+	fv, err := readConfigFile()
+	if err != nil {
+		l.Fatal().Str("configFile", getConfigPath()).Err(err).Send()
+	}
+	return fv.GetStringSlice("timezone")
+}
+
+var zonesCmd = &cobra.Command{
+	Use:   "zones",
+	Short: "Manage the time zones saved in the config file",
+	Long: `Add, remove, and list the time zones saved in the config file, which are shown when timeBuddy is run without
+--timezone.
+
+Examples:
+
+  # Add time zones, keeping the existing order:
+  $ timeBuddy zones add Europe/Berlin Asia/Tokyo
+
+  # Remove a time zone:
+  $ timeBuddy zones remove Europe/Berlin
+
+  # Remove all time zones:
+  $ timeBuddy zones clear`,
+}
+
+var zonesAddCmd = &cobra.Command{
+	Use:               "add <timezone>...",
+	Short:             "Add time zones to the config file",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTimezone,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, tz := range args {
+			if _, err := loadLocation(tz); err != nil {
+				l.Fatal().Str("timezone", tz).Err(err).Send()
+			}
+		}
+		saveConfiguredTimezones(deduplicateSlice(append(configuredTimezones(), args...)))
+	},
+}
+
+var zonesRemoveCmd = &cobra.Command{
+	Use:   "remove <timezone>...",
+	Short: "Remove time zones from the config file",
+	Args:  cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return configuredTimezones(), cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		tzs := configuredTimezones()
+		for _, tz := range args {
+			i := slices.Index(tzs, tz)
+			if i < 0 {
+				l.Fatal().Str("timezone", tz).Err(fmt.Errorf("timezone is not in the config file")).Send()
+			}
+			tzs = slices.Delete(tzs, i, i+1)
+		}
+		saveConfiguredTimezones(tzs)
+	},
+}
+
+var zonesClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all time zones from the config file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		saveConfiguredTimezones([]string{})
+	},
+}
+
+var zonesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the time zones in the config file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printConfiguredTimezones(configuredTimezones())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(zonesCmd)
+	zonesCmd.AddCommand(zonesAddCmd, zonesRemoveCmd, zonesClearCmd, zonesListCmd)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2024 Jake Rogers <code@supportoss.org>
+*/
+package buildinfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Version, Commit, and Date are set at build time with -ldflags, i.e.
+// -X github.com/JakeTRogers/timeBuddy/internal/buildinfo.Commit=abc1234
+var (
+	Version = "v1.1.9"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info is the build metadata of the binary.
+type Info struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	Date          string `json:"date"`
+	GoVersion     string `json:"go_version"`
+	TZDataVersion string `json:"tzdata_version"`
+}
+
+// Get returns the build metadata. When the commit and build date weren't set with -ldflags, they are read from the
+// version control information embedded by go build, and are "unknown" if that isn't available either.
+func Get() Info {
+	info := Info{
+		Version:       Version,
+		Commit:        Commit,
+		Date:          Date,
+		GoVersion:     runtime.Version(),
+		TZDataVersion: tzdataVersion(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Date == "":
+				info.Date = s.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.Date == "" {
+		info.Date = "unknown"
+	}
+	return info
+}
+
+// tzdataVersion returns the version of the timezone database used to load timezones, i.e. 2024a. Go reads the
+// database from $ZONEINFO or the system zoneinfo directory, both of which record the version in their tzdata.zi file.
+// It returns "unknown" if the version can't be found, i.e. on Windows, where Go uses its own copy of the database.
+func tzdataVersion() string {
+	dirs := []string{"/usr/share/zoneinfo", "/usr/share/lib/zoneinfo", "/usr/lib/locale/TZ"}
+	if dir := os.Getenv("ZONEINFO"); dir != "" {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		f, err := os.Open(filepath.Join(dir, "tzdata.zi"))
+		if err != nil {
+			continue
+		}
+		line, _ := bufio.NewReader(f).ReadString('\n')
+		f.Close()
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "# version "); ok {
+			return version
+		}
+	}
+	return "unknown"
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -9,24 +10,55 @@ import (
 	"github.com/rs/zerolog/pkgerrors"
 )
 
-var log zerolog.Logger
+var (
+	log    zerolog.Logger
+	format           = "text"
+	out    io.Writer = os.Stderr
+)
 
 func init() {
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 	zerolog.TimeFieldFormat = time.RFC3339
 
-	var output io.Writer = zerolog.ConsoleWriter{
-		Out:        os.Stderr,
-		TimeFormat: time.RFC3339,
+	newLogger(zerolog.ErrorLevel)
+}
+
+// newLogger replaces the shared logger with one writing to out in the current format, at the given level. The logger
+// returned by GetLogger points at the shared logger, so callers see the change.
+func newLogger(level zerolog.Level) {
+	var output io.Writer = out
+	if format != "json" {
+		output = zerolog.ConsoleWriter{
+			Out:        out,
+			TimeFormat: time.RFC3339,
+			// colors are only useful on a terminal
+			NoColor: out != os.Stderr,
+		}
 	}
 
 	log = zerolog.New(output).
-		Level(zerolog.ErrorLevel).
+		Level(level).
 		With().
 		Timestamp().
 		Logger()
 }
 
+// SetFormat switches the log output between human-readable text and structured json.
+func SetFormat(f string) error {
+	if f != "text" && f != "json" {
+		return fmt.Errorf("invalid log format %q, expected text or json", f)
+	}
+	format = f
+	newLogger(log.GetLevel())
+	return nil
+}
+
+// SetOutput writes the log output to w instead of stderr.
+func SetOutput(w io.Writer) {
+	out = w
+	newLogger(log.GetLevel())
+}
+
 func GetLogger() *zerolog.Logger {
 	return &log
 }
@@ -47,3 +79,9 @@ func SetLogLevel(verboseCount int) {
 	}
 	log = log.Level(level)
 }
+
+// SetQuiet limits the log output to fatal errors, for --quiet. Disabling the logger entirely would also stop Fatal from
+// exiting, so fatal errors are still written.
+func SetQuiet() {
+	log = log.Level(zerolog.FatalLevel)
+}